@@ -1,37 +1,88 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
 	"stockify/internal/config"
 	"stockify/internal/database"
+	"stockify/internal/logging"
 	"stockify/internal/store"
 	"stockify/internal/tasks"
+	"strconv"
+	"syscall"
 )
 
+const usage = `Uso: datasync [comando]
+
+Comandos:
+  sync           puebla la base de datos desde la API externa si está vacía (por defecto)
+  archive [n]    archiva los eventos con más de n meses (por defecto RETENTION_MONTHS)`
+
 func main() {
-	log.Println("Iniciando script de sincronización de datos CLI...")
+	command := "sync"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	if command != "sync" && command != "archive" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	logging.Setup(os.Stderr)
+	slog.Info("Iniciando script de sincronización de datos CLI", "command", command)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	cfg := config.Load()
+	logging.SetLevel(cfg.LogLevel)
 	db := database.Connect(cfg.DatabaseURL)
+	if err := database.CheckSchema(ctx, db); err != nil {
+		logging.Fatal("El esquema de la base de datos no está al día, ejecuta primero el comando migrate", "error", err)
+	}
+
+	stockSt := store.NewStockStore(db, cfg.QueryTimeout)
 
-	stockSt := store.NewStockStore(db)
+	switch command {
+	case "sync":
+		runSync(ctx, cfg, stockSt)
+	case "archive":
+		months := cfg.RetentionMonths
+		if len(os.Args) > 2 {
+			n, err := strconv.Atoi(os.Args[2])
+			if err != nil || n <= 0 {
+				logging.Fatal("Número de meses inválido", "months", os.Args[2])
+			}
+			months = n
+		}
+
+		if _, err := tasks.NewRetentionService(stockSt, months).Run(ctx); err != nil {
+			logging.Fatal("Falló la ejecución de la política de retención", "error", err)
+		}
+	}
 
-	count, err := stockSt.CountStocks()
+	slog.Info("Script de sincronización de datos CLI finalizado")
+}
+
+func runSync(ctx context.Context, cfg *config.Config, stockSt *store.StockStore) {
+	count, err := stockSt.CountStocks(ctx)
 	if err != nil {
-		log.Fatalf("Error al verificar el conteo de stocks en la base de datos: %v", err)
+		logging.Fatal("Error al verificar el conteo de stocks en la base de datos", "error", err)
 	}
 
 	if count > 0 {
-		log.Printf("La base de datos ya contiene %d registros de stocks. No se ejecutará la población.", count)
-	} else {
-		log.Println("La base de datos está vacía o no contiene registros de stocks. Iniciando población...")
-		dataSyncSvc := tasks.NewDataSyncService(db, cfg)
-		err := dataSyncSvc.RunPopulation()
-		if err != nil {
-			log.Fatalf("Falló la ejecución de la población de datos: %v", err)
-		}
-		log.Println("Población de datos completada exitosamente.")
+		slog.Info("La base de datos ya contiene registros de stocks, no se ejecutará la población", "count", count)
+		return
 	}
 
-	log.Println("Script de sincronización de datos CLI finalizado.")
+	slog.Info("La base de datos no contiene registros de stocks, iniciando población")
+	dataSyncSvc := tasks.NewDataSyncService(stockSt, stockSt, cfg)
+	// RunPopulation logs why the run failed, along with its run ID.
+	if err := dataSyncSvc.RunPopulation(ctx); err != nil {
+		os.Exit(1)
+	}
+	slog.Info("Población de datos completada exitosamente")
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"stockify/internal/config"
+	"stockify/internal/database"
+	"stockify/internal/logging"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up          apply every pending migration
+  down [n]    revert the last n applied migrations (default 1)
+  status      list migrations and whether they are applied`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	logging.Setup(os.Stderr)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	logging.SetLevel(cfg.LogLevel)
+	db := database.Connect(cfg.DatabaseURL)
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := database.MigrateUp(ctx, db)
+		if err != nil {
+			logging.Fatal("Migration failed", "applied", applied, "error", err)
+		}
+		slog.Info("Migrations applied", "applied", applied)
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			n, err := strconv.Atoi(os.Args[2])
+			if err != nil || n <= 0 {
+				logging.Fatal("Invalid number of steps", "steps", os.Args[2])
+			}
+			steps = n
+		}
+
+		reverted, err := database.MigrateDown(ctx, db, steps)
+		if err != nil {
+			logging.Fatal("Rollback failed", "reverted", reverted, "error", err)
+		}
+		slog.Info("Migrations reverted", "reverted", reverted)
+	case "status":
+		statuses, err := database.Status(ctx, db)
+		if err != nil {
+			logging.Fatal("Could not read migration status", "error", err)
+		}
+
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
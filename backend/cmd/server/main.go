@@ -1,26 +1,255 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"stockify/internal/api"
+	"stockify/internal/api/rpc"
 	"stockify/internal/config"
 	"stockify/internal/database"
+	"stockify/internal/logging"
+	"stockify/internal/metrics"
 	"stockify/internal/services"
 	"stockify/internal/store"
+	"stockify/internal/stream"
+	"stockify/internal/tasks"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 )
 
 func main() {
-	cfg := config.Load()
-	db := database.Connect(cfg.DatabaseURL)
-	stockStore := store.NewStockStore(db)
+	demo := flag.Bool("demo", false, "serve stocks from an in-memory fixture instead of the database")
+	fixture := flag.String("fixture", "", "JSON fixture loaded in demo mode (defaults to the bundled sample data)")
+	flag.Parse()
+
+	logging.Setup(os.Stderr)
+
+	// ctx is cancelled on SIGINT or SIGTERM, and background workers stop
+	// with it.
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	ctx, stopWorkers := context.WithCancel(signalCtx)
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	var db *gorm.DB
+	var cfg *config.Config
+	var stockStore store.StockStoreInterface
+	// feedStore is stockStore without the cache, polled for new events.
+	var feedStore store.StockStoreInterface
+	var auditStore store.AuditStoreInterface
+	var healthStockStore store.StockCounterInterface
+	var syncStore store.SyncStoreInterface
+	var pinger store.PingerInterface
+	var cacheStats api.CacheStatsProvider
+	broker := stream.NewBroker()
+	appMetrics := metrics.New()
+
+	if *demo {
+		cfg = config.LoadDemo()
+		logging.SetLevel(cfg.LogLevel)
+		memoryStore := loadDemoStore(*fixture)
+		stockStore = memoryStore
+		feedStore = memoryStore
+		auditStore = memoryStore
+		healthStockStore = memoryStore
+		syncStore = memoryStore
+		pinger = memoryStore
+	} else {
+		cfg = config.Load()
+		logging.SetLevel(cfg.LogLevel)
+		db = database.Connect(cfg.DatabaseURL)
+		if err := database.CheckSchema(ctx, db); err != nil {
+			logging.Fatal("Database schema check failed, run the migrate command first", "error", err)
+		}
+		if sqlDB, err := db.DB(); err != nil {
+			slog.Warn("Database pool metrics disabled", "error", err)
+		} else if err := appMetrics.RegisterDB("stockify", sqlDB); err != nil {
+			slog.Warn("Database pool metrics disabled", "error", err)
+		}
+
+		dbStore := store.NewStockStore(db, cfg.QueryTimeout)
+		cachingStore := store.NewCachingStore(dbStore, cfg.CacheSize, cfg.CacheTTL)
+		stockStore = cachingStore
+		feedStore = dbStore
+		auditStore = dbStore
+		healthStockStore = dbStore
+		syncStore = dbStore
+		pinger = dbStore
+		cacheStats = cachingStore
+
+		if cfg.CacheCheckInterval > 0 {
+			cacheRefresh := tasks.NewCacheRefreshService(dbStore, cachingStore, cfg.CacheCheckInterval)
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				cacheRefresh.Run(ctx)
+			}()
+		}
+	}
+
+	if err := appMetrics.RegisterSync(syncStore); err != nil {
+		slog.Warn("Sync metrics disabled", "error", err)
+	}
+
+	if cfg.StreamPollInterval > 0 {
+		streamFeed := tasks.NewStreamFeedService(feedStore, broker, cfg.StreamPollInterval)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			streamFeed.Run(ctx)
+		}()
+	}
+
 	stockService := services.NewStockService(stockStore)
 	recommendationService := services.NewRecommendationService(stockStore)
-	router := api.NewRouter(stockService, recommendationService)
+	recommendationService.SetMetrics(appMetrics)
+	auditService := services.NewAuditService(auditStore)
+	healthService := services.NewHealthService(healthStockStore, syncStore, pinger, cfg.DataStaleAfter)
+	importService := tasks.NewImportService(stockStore)
+	router := api.NewRouter(cfg, stockService, recommendationService, auditService, healthService, importService, broker, cacheStats, appMetrics)
+
+	httpServer := &http.Server{
+		Addr:         cfg.ServerPort,
+		Handler:      router,
+		ReadTimeout:  cfg.HTTPReadTimeout,
+		WriteTimeout: cfg.HTTPWriteTimeout,
+		IdleTimeout:  cfg.HTTPIdleTimeout,
+		ErrorLog:     slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+	// Event streams never finish on their own; closing the broker ends them
+	// so that Shutdown does not wait for them until the deadline.
+	httpServer.RegisterOnShutdown(broker.Close)
+
+	grpcListener, err := net.Listen("tcp", cfg.GRPCPort)
+	if err != nil {
+		logging.Fatal("Could not listen for gRPC", "port", cfg.GRPCPort, "error", err)
+	}
+	grpcServer := rpc.NewGRPCServer(stockService, recommendationService)
+
+	serverErrors := make(chan error, 2)
+	go func() {
+		slog.Info("Starting gRPC server", "port", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			serverErrors <- fmt.Errorf("gRPC server: %w", err)
+		}
+	}()
+	go func() {
+		slog.Info("Starting server", "port", cfg.ServerPort)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- fmt.Errorf("HTTP server: %w", err)
+		}
+	}()
+
+	exitCode := 0
+	select {
+	case <-signalCtx.Done():
+		slog.Info("Shutdown signal received, draining connections", "timeout", cfg.ShutdownTimeout.String())
+	case err := <-serverErrors:
+		slog.Error("Could not start server", "error", err)
+		exitCode = 1
+	}
+	// A second signal terminates the process right away.
+	stopSignals()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	if !shutdown(shutdownCtx, httpServer, grpcServer, stopWorkers, &workers, db) {
+		exitCode = 1
+	}
+	cancel()
 
-	log.Printf("Starting server on port %s\n", cfg.ServerPort)
-	if err := http.ListenAndServe(cfg.ServerPort, router); err != nil {
-		log.Fatalf("Could not start server: %s\n", err)
+	slog.Info("Server stopped")
+	os.Exit(exitCode)
+}
+
+// shutdown stops accepting requests, waits until ctx is done for the
+// in-flight ones and the background workers to finish, and then closes the
+// database pool. It reports whether everything stopped in time.
+func shutdown(ctx context.Context, httpServer *http.Server, grpcServer *grpc.Server, stopWorkers context.CancelFunc, workers *sync.WaitGroup, db *gorm.DB) bool {
+	clean := true
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		slog.Warn("HTTP server did not drain in time, closing remaining connections", "error", err)
+		httpServer.Close()
+		clean = false
+	}
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		slog.Warn("gRPC server did not drain in time, closing remaining connections")
+		grpcServer.Stop()
+		clean = false
+	}
+
+	stopWorkers()
+	if !waitTimeout(ctx, workers) {
+		slog.Warn("Background workers did not stop in time")
+		clean = false
+	}
+
+	if db != nil {
+		if err := database.Close(db); err != nil {
+			slog.Error("Could not close the database pool", "error", err)
+			clean = false
+		}
+	}
+
+	return clean
+}
+
+// waitTimeout waits for wg until ctx is done, reporting whether wg finished.
+func waitTimeout(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
+
+func loadDemoStore(fixturePath string) *store.MemoryStore {
+	var fixture io.Reader = store.DemoFixture()
+
+	if fixturePath != "" {
+		file, err := os.Open(fixturePath)
+		if err != nil {
+			logging.Fatal("Could not open demo fixture", "error", err)
+		}
+		defer file.Close()
+		fixture = file
+	}
+
+	memoryStore, err := store.LoadMemoryStore(fixture)
+	if err != nil {
+		logging.Fatal("Could not load demo fixture", "error", err)
+	}
+
+	count, _ := memoryStore.CountStocks(context.Background())
+	slog.Info("Demo mode: serving stock events from memory", "count", count)
+
+	return memoryStore
+}
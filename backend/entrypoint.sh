@@ -3,6 +3,10 @@
 
 set -e
 
+echo "Backend Entrypoint: Applying database migrations..."
+
+/app/stockify_migrate up
+
 echo "Backend Entrypoint: Starting data synchronization task..."
 
 /app/stockify_datasync
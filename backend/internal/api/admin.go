@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"stockify/internal/tasks"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// requireAdmin rejects requests that do not carry one of keys in the
+// X-API-Key header or as a Bearer token. The key holder is stored in the
+// request context as the actor of any change the request makes.
+func requireAdmin(keys map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("X-API-Key")
+			if key == "" {
+				key, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			}
+
+			holder, ok := lookupAPIKey(keys, key)
+			if !ok {
+				respondWithError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized, i18n.MsgInvalidAdminCredentials)
+				return
+			}
+
+			ctx := core.WithActor(r.Context(), core.Actor{Type: core.ActorTypeAPIKey, ID: holder})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// lookupAPIKey compares key against every configured key in constant time.
+func lookupAPIKey(keys map[string]string, key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	var holder string
+	found := false
+	for candidate, name := range keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			holder, found = name, true
+		}
+	}
+
+	return holder, found
+}
+
+func adminFromContext(ctx context.Context) string {
+	return core.ActorFromContext(ctx).ID
+}
+
+type AdminHandler struct {
+	stockService  *services.StockService
+	auditService  *services.AuditService
+	importService *tasks.ImportService
+}
+
+func NewAdminHandler(ss *services.StockService, as *services.AuditService, is *tasks.ImportService) *AdminHandler {
+	return &AdminHandler{stockService: ss, auditService: as, importService: is}
+}
+
+func parseStockID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id == 0 {
+		respondWithValidationError(w, r, i18n.MsgInvalidID, newFieldError("id", i18n.MsgFieldPositiveInteger, nil))
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+func (h *AdminHandler) GetDeletedStocks(w http.ResponseWriter, r *http.Request) {
+	params := parseListParams(r)
+
+	stocks, totalItems, err := h.stockService.ListDeletedStocks(r.Context(), params)
+	if respondWithInvalidSort(w, r, err) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error en ListDeletedStocks service", "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgListDeletedStocksFailed)
+		return
+	}
+
+	respondWithPage(w, r, newStockResponses(stocks), totalItems, params)
+}
+
+func (h *AdminHandler) DeleteStock(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseStockID(w, r)
+	if !ok {
+		return
+	}
+
+	stock, err := h.stockService.DeleteStock(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error en DeleteStock service", "id", id, "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgDeleteStockFailed)
+		return
+	}
+	if stock == nil {
+		respondWithError(w, r, http.StatusNotFound, ErrCodeStockNotFound, i18n.MsgStockNotFound)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Admin eliminó el evento de stock", "admin", adminFromContext(r.Context()), "id", id, "ticker", stock.Ticker)
+	respondWithJSON(w, r, http.StatusOK, newStockResponse(*stock))
+}
+
+func (h *AdminHandler) RestoreStock(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseStockID(w, r)
+	if !ok {
+		return
+	}
+
+	stock, err := h.stockService.RestoreStock(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error en RestoreStock service", "id", id, "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgRestoreStockFailed)
+		return
+	}
+	if stock == nil {
+		respondWithError(w, r, http.StatusNotFound, ErrCodeDeletedStockNotFound, i18n.MsgDeletedStockNotFound)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Admin restauró el evento de stock", "admin", adminFromContext(r.Context()), "id", id, "ticker", stock.Ticker)
+	respondWithJSON(w, r, http.StatusOK, newStockResponse(*stock))
+}
+
+func (h *AdminHandler) PurgeStock(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseStockID(w, r)
+	if !ok {
+		return
+	}
+
+	purged, err := h.stockService.PurgeStock(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error en PurgeStock service", "id", id, "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgPurgeStockFailed)
+		return
+	}
+	if !purged {
+		respondWithError(w, r, http.StatusNotFound, ErrCodeDeletedStockNotFound, i18n.MsgDeletedStockNotFound)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Admin eliminó definitivamente el evento de stock", "admin", adminFromContext(r.Context()), "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseAuditParams reads the audit log filters. from and to are RFC 3339
+// timestamps bounding created_at as [from, to).
+func parseAuditParams(r *http.Request) (store.AuditQueryParams, []FieldError) {
+	queryParams := r.URL.Query()
+	listParams := parseListParams(r)
+
+	params := store.AuditQueryParams{
+		ActorType: queryParams.Get("actor_type"),
+		ActorID:   queryParams.Get("actor_id"),
+		Action:    queryParams.Get("action"),
+		Ticker:    queryParams.Get("ticker"),
+		Page:      listParams.Page,
+		PageSize:  listParams.PageSize,
+	}
+
+	var fieldErrors []FieldError
+
+	if raw := queryParams.Get("stock_id"); raw != "" {
+		stockID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			fieldErrors = append(fieldErrors, newFieldError("stock_id", i18n.MsgFieldNumeric, nil))
+		}
+		params.StockID = uint(stockID)
+	}
+
+	for _, bound := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &params.From}, {"to", &params.To}} {
+		if raw := queryParams.Get(bound.name); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				fieldErrors = append(fieldErrors, newFieldError(bound.name, i18n.MsgFieldRFC3339, nil))
+			}
+			*bound.target = parsed
+		}
+	}
+
+	return params, fieldErrors
+}
+
+func (h *AdminHandler) GetAuditEntries(w http.ResponseWriter, r *http.Request) {
+	params, fieldErrors := parseAuditParams(r)
+	if len(fieldErrors) > 0 {
+		respondWithValidationError(w, r, i18n.MsgInvalidAuditFilters, fieldErrors...)
+		return
+	}
+
+	entries, totalItems, err := h.auditService.ListEntries(r.Context(), params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error en ListEntries service", "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgAuditFailed)
+		return
+	}
+
+	responses, err := newAuditEntryResponses(entries)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error convirtiendo entradas de auditoría", "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgAuditFailed)
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, paginatedResponse("entries", responses, totalItems, params.Page, params.PageSize))
+}
+
+// maxImportBytes bounds the size of the CSV accepted by ImportStocks.
+const maxImportBytes = 10 << 20
+
+// ImportStocks loads rating events from a CSV sent as the request body or as
+// the "file" field of a multipart form. With dry_run=true the rows are only
+// validated.
+func (h *AdminHandler) ImportStocks(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	if err != nil && r.URL.Query().Get("dry_run") != "" {
+		respondWithValidationError(w, r, i18n.MsgInvalidDryRun, newFieldError("dry_run", i18n.MsgFieldBoolean, nil))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	var file io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		formFile, _, err := r.FormFile("file")
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respondWithError(w, r, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge, i18n.MsgCSVTooLarge)
+				return
+			}
+			respondWithValidationError(w, r, i18n.MsgMissingCSV, newFieldError("file", i18n.MsgFieldRequiredMultipart, nil))
+			return
+		}
+		defer formFile.Close()
+		file = formFile
+	}
+
+	report, err := h.importService.Import(r.Context(), file, dryRun)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		var fileErr *tasks.InvalidImportFileError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			respondWithError(w, r, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge, i18n.MsgCSVTooLarge)
+		case errors.As(err, &fileErr):
+			lang := i18n.FromContext(r.Context())
+			detail := i18n.T(lang, i18n.MsgInvalidImportFile, i18n.Params{"reason": fileErr.Reason.Localize(lang)})
+			respondWithProblem(w, r, Problem{Status: http.StatusBadRequest, Code: ErrCodeInvalidImportFile, Detail: detail})
+		default:
+			slog.ErrorContext(r.Context(), "Error en Import service", "error", err)
+			respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgImportFailed)
+		}
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Admin importó un CSV", "admin", adminFromContext(r.Context()), "dry_run", dryRun, "accepted", report.Accepted, "rejected", report.Rejected)
+	respondWithJSON(w, r, http.StatusOK, newImportReportResponse(report, i18n.FromContext(r.Context())))
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"stockify/internal/tasks"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	keys := map[string]string{"secret-key": "alice"}
+	var holder string
+	handler := requireAdmin(keys)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		holder = adminFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{"missing credentials", "", "", http.StatusUnauthorized},
+		{"wrong key", "X-API-Key", "other", http.StatusUnauthorized},
+		{"api key header", "X-API-Key", "secret-key", http.StatusNoContent},
+		{"bearer token", "Authorization", "Bearer secret-key", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holder = ""
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/stocks/deleted", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusNoContent {
+				assert.Equal(t, "alice", holder)
+			}
+		})
+	}
+}
+
+func TestImportStocks_LocalizesErrors(t *testing.T) {
+	memoryStore := store.NewMemoryStore(nil)
+	handler := negotiateLanguage(http.HandlerFunc(NewAdminHandler(
+		services.NewStockService(memoryStore),
+		services.NewAuditService(memoryStore),
+		tasks.NewImportService(memoryStore),
+	).ImportStocks))
+
+	csv := "ticker,target_from,target_to,company,action,brokerage,rating_from,rating_to,time\n" +
+		",abc,,Nameless,initiated by,Broker A,,Buy,2025-05-01T12:00:00Z\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/import?dry_run=true&lang=en", strings.NewReader(csv))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report ImportReportResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	require.Len(t, report.Rows, 1)
+	assert.Equal(t, []string{`target_from: "abc" is not a valid amount`, "ticker: is required"}, report.Rows[0].Errors)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/import", strings.NewReader("ticker,price\n"))
+	req.Header.Set("Accept-Language", "en")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	var problem Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, ErrCodeInvalidImportFile, problem.Code)
+	assert.True(t, strings.HasPrefix(problem.Detail, `Invalid CSV file: unknown column "price", expected columns: ticker,`), problem.Detail)
+}
+
+func TestImportStocks_TooLarge(t *testing.T) {
+	memoryStore := store.NewMemoryStore(nil)
+	handler := NewAdminHandler(
+		services.NewStockService(memoryStore),
+		services.NewAuditService(memoryStore),
+		tasks.NewImportService(memoryStore),
+	)
+
+	csv := "ticker,target_from,target_to,company,action,brokerage,rating_from,rating_to,time\n" +
+		strings.Repeat("AAPL,$1,$2,Apple Inc.,upgraded by,Broker A,Hold,Buy,2025-05-01T12:00:00Z\n", maxImportBytes/70)
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("file", "stocks.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(csv))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	multipartReq := httptest.NewRequest(http.MethodPost, "/api/admin/import?dry_run=true", &form)
+	multipartReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	for name, req := range map[string]*http.Request{
+		"raw":       httptest.NewRequest(http.MethodPost, "/api/admin/import?dry_run=true", strings.NewReader(csv)),
+		"multipart": multipartReq,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ImportStocks(rec, req)
+
+			require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+			var problem Problem
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+			assert.Equal(t, ErrCodePayloadTooLarge, problem.Code)
+		})
+	}
+}
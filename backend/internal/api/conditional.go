@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl returns the Cache-Control value for responses that may be
+// cached for maxAge. Without a max-age clients must revalidate every time,
+// which still saves the body thanks to the validators.
+func cacheControl(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+}
+
+// etagFor builds a weak ETag for data last changed at lastModified. variant
+// distinguishes representations that also depend on something else, such as
+// the current date.
+func etagFor(lastModified time.Time, variant string) string {
+	tag := strconv.FormatInt(lastModified.UnixNano(), 36)
+	if variant != "" {
+		tag += "-" + variant
+	}
+	return `W/"` + tag + `"`
+}
+
+// checkNotModified sets ETag, Last-Modified and Cache-Control on a cacheable
+// GET response and answers 304 Not Modified when the request's If-None-Match
+// or, failing that, If-Modified-Since shows the client's copy is current.
+// It reports whether it responded. If the data version cannot be read the
+// request is served without validators.
+func (h *StockHandler) checkNotModified(w http.ResponseWriter, r *http.Request, variant string) bool {
+	lastModified, err := h.stockService.LastModified(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error en LastModified service", "error", err)
+		return false
+	}
+
+	etag := etagFor(lastModified, variant)
+	header := w.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", cacheControl(h.cacheMaxAge))
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatches(inm, etag) {
+			return false
+		}
+	} else if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err != nil || lastModified.IsZero() || lastModified.Truncate(time.Second).After(ims) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches applies the weak comparison of RFC 9110 to an If-None-Match
+// header value.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
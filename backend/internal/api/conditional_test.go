@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/core"
+	"stockify/internal/store"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	modified := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	memoryStore := store.NewMemoryStore([]core.Stock{
+		{Model: gorm.Model{CreatedAt: modified, UpdatedAt: modified}, Ticker: "AAPL", Company: "Apple Inc.", RatingTo: "Buy", Time: modified},
+	})
+	handler := newTestStockHandler(memoryStore, 90*time.Second)
+
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stocks", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.GetStocks(rec, req)
+		return rec
+	}
+
+	first := get(nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "Thu, 01 May 2025 12:00:00 GMT", first.Header().Get("Last-Modified"))
+	assert.Equal(t, "public, max-age=90", first.Header().Get("Cache-Control"))
+
+	notModified := get(map[string]string{"If-None-Match": `"other", ` + etag})
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	assert.Equal(t, http.StatusNotModified, get(map[string]string{"If-Modified-Since": "Thu, 01 May 2025 12:00:00 GMT"}).Code)
+	assert.Equal(t, http.StatusOK, get(map[string]string{"If-Modified-Since": "Thu, 01 May 2025 11:59:59 GMT"}).Code)
+	assert.Equal(t, http.StatusOK, get(map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Thu, 01 May 2025 12:00:00 GMT"}).Code,
+		"If-Modified-Since is ignored when If-None-Match is present")
+
+	_, err := memoryStore.SoftDeleteStock(context.Background(), 1)
+	require.NoError(t, err)
+
+	changed := get(map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+}
+
+func TestConditionalRequests_RecommendationsVaryByDay(t *testing.T) {
+	handler := newTestStockHandler(store.NewMemoryStore([]core.Stock{{Ticker: "AAPL", RatingTo: "Buy", Time: time.Now()}}), 0)
+
+	rec := httptest.NewRecorder()
+	handler.GetRecommendations(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stocks/recommendations", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("ETag"), time.Now().UTC().Format("20060102"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+}
+
+func TestConditionalRequests_UnknownTickerIsNotFound(t *testing.T) {
+	modified := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	handler := newTestStockHandler(store.NewMemoryStore([]core.Stock{
+		{Model: gorm.Model{CreatedAt: modified, UpdatedAt: modified}, Ticker: "AAPL", Time: modified},
+	}), 0)
+	router := chi.NewRouter()
+	router.Get("/api/v1/stocks/{ticker}", handler.GetStockByTicker)
+
+	get := func(ticker string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stocks/"+ticker, nil)
+		req.Header.Set("If-Modified-Since", "Thu, 01 May 2025 12:00:00 GMT")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotModified, get("AAPL").Code)
+	assert.Equal(t, http.StatusNotFound, get("NOPE").Code)
+}
+
+func TestConditionalRequests_InvalidSortIsRejected(t *testing.T) {
+	modified := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	handler := newTestStockHandler(store.NewMemoryStore([]core.Stock{
+		{Model: gorm.Model{CreatedAt: modified, UpdatedAt: modified}, Ticker: "AAPL", Time: modified},
+	}), 0)
+
+	first := httptest.NewRecorder()
+	handler.GetStocks(first, httptest.NewRequest(http.MethodGet, "/api/v1/stocks", nil))
+	require.Equal(t, http.StatusOK, first.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stocks?sortBy=password", nil)
+	req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	rec := httptest.NewRecorder()
+	handler.GetStocks(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"encoding/json"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/services"
+	"stockify/internal/tasks"
+	"time"
+)
+
+// The types below are the JSON contract of the API. Handlers map domain
+// values into them instead of serializing core and service types, so that
+// changes to the database model do not leak into responses. Every member
+// name is snake_case; golden files under testdata/golden lock the encoding.
+
+// StockResponse is a rating event. Nullable members are always present.
+type StockResponse struct {
+	ID         uint       `json:"id"`
+	Ticker     string     `json:"ticker"`
+	Company    string     `json:"company"`
+	Brokerage  string     `json:"brokerage"`
+	Action     string     `json:"action"`
+	RatingFrom *string    `json:"rating_from"`
+	RatingTo   string     `json:"rating_to"`
+	TargetFrom *float64   `json:"target_from"`
+	TargetTo   *float64   `json:"target_to"`
+	Time       time.Time  `json:"time"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+// LatestStockResponse is the most recent event of a ticker, as listed by
+// view=latest, with the number of events of the ticker.
+type LatestStockResponse struct {
+	StockResponse
+	EventCount int64 `json:"event_count"`
+}
+
+// RecommendationResponse is a recommended event with its score and the
+// reasons behind it.
+type RecommendationResponse struct {
+	StockResponse
+	Score   float64                        `json:"score"`
+	Reasons []RecommendationReasonResponse `json:"reasons"`
+}
+
+// RecommendationReasonResponse is a reason worded in the language of the
+// request, along with the values it refers to so that clients may word it
+// themselves.
+type RecommendationReasonResponse struct {
+	Type    string      `json:"type"`
+	Details string      `json:"details"`
+	Params  i18n.Params `json:"params"`
+}
+
+type RecommendationsResponse struct {
+	Recommendations []RecommendationResponse `json:"recommendations"`
+}
+
+// AuditEntryResponse is an audit log entry. Before and After hold the event
+// as it was before and after the change, in the StockResponse format.
+type AuditEntryResponse struct {
+	ID        uint           `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	ActorType string         `json:"actor_type"`
+	ActorID   string         `json:"actor_id"`
+	Action    string         `json:"action"`
+	StockID   uint           `json:"stock_id"`
+	Ticker    string         `json:"ticker"`
+	Before    *StockResponse `json:"before"`
+	After     *StockResponse `json:"after"`
+}
+
+func newStockResponse(stock core.Stock) StockResponse {
+	response := StockResponse{
+		ID:         stock.ID,
+		Ticker:     stock.Ticker,
+		Company:    stock.Company,
+		Brokerage:  stock.Brokerage,
+		Action:     stock.Action,
+		RatingFrom: stock.RatingFrom,
+		RatingTo:   stock.RatingTo,
+		TargetFrom: stock.TargetFrom,
+		TargetTo:   stock.TargetTo,
+		Time:       stock.Time,
+		CreatedAt:  stock.CreatedAt,
+		UpdatedAt:  stock.UpdatedAt,
+	}
+	if stock.DeletedAt.Valid {
+		deletedAt := stock.DeletedAt.Time
+		response.DeletedAt = &deletedAt
+	}
+	return response
+}
+
+func newStockResponses(stocks []core.Stock) []StockResponse {
+	responses := make([]StockResponse, 0, len(stocks))
+	for _, stock := range stocks {
+		responses = append(responses, newStockResponse(stock))
+	}
+	return responses
+}
+
+func newLatestStockResponses(stocks []core.LatestStock) []LatestStockResponse {
+	responses := make([]LatestStockResponse, 0, len(stocks))
+	for _, stock := range stocks {
+		responses = append(responses, LatestStockResponse{StockResponse: newStockResponse(stock.Stock), EventCount: stock.EventCount})
+	}
+	return responses
+}
+
+func newRecommendationsResponse(recommendations []services.RecommendedStock, lang i18n.Lang) RecommendationsResponse {
+	response := RecommendationsResponse{Recommendations: make([]RecommendationResponse, 0, len(recommendations))}
+
+	for _, recommendation := range recommendations {
+		reasons := make([]RecommendationReasonResponse, 0, len(recommendation.Reasons))
+		for _, reason := range recommendation.Reasons {
+			reasons = append(reasons, RecommendationReasonResponse{Type: string(reason.Type), Details: reason.Localize(lang), Params: reason.Params})
+		}
+
+		response.Recommendations = append(response.Recommendations, RecommendationResponse{
+			StockResponse: newStockResponse(recommendation.Stock),
+			Score:         recommendation.Score,
+			Reasons:       reasons,
+		})
+	}
+
+	return response
+}
+
+// ImportReportResponse summarizes a CSV import and lists the result of every
+// row, with the reasons a row was rejected worded in the language of the
+// request.
+type ImportReportResponse struct {
+	DryRun   bool                `json:"dry_run"`
+	Accepted int                 `json:"accepted"`
+	Rejected int                 `json:"rejected"`
+	Rows     []ImportRowResponse `json:"rows"`
+}
+
+type ImportRowResponse struct {
+	Row     int      `json:"row"`
+	Ticker  string   `json:"ticker"`
+	Status  string   `json:"status"`
+	StockID uint     `json:"stock_id,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+func newImportReportResponse(report *tasks.ImportReport, lang i18n.Lang) ImportReportResponse {
+	response := ImportReportResponse{
+		DryRun:   report.DryRun,
+		Accepted: report.Accepted,
+		Rejected: report.Rejected,
+		Rows:     make([]ImportRowResponse, 0, len(report.Rows)),
+	}
+
+	for _, row := range report.Rows {
+		var messages []string
+		for _, rowErr := range row.Errors {
+			messages = append(messages, rowErr.Localize(lang))
+		}
+		response.Rows = append(response.Rows, ImportRowResponse{Row: row.Row, Ticker: row.Ticker, Status: row.Status, StockID: row.StockID, Errors: messages})
+	}
+
+	return response
+}
+
+// newAuditEntryResponses maps audit entries, converting the stored snapshots
+// of the event, which are core.Stock JSON, into StockResponse.
+func newAuditEntryResponses(entries []core.AuditEntry) ([]AuditEntryResponse, error) {
+	responses := make([]AuditEntryResponse, 0, len(entries))
+
+	for _, entry := range entries {
+		before, err := auditSnapshot(entry.Before)
+		if err != nil {
+			return nil, err
+		}
+		after, err := auditSnapshot(entry.After)
+		if err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, AuditEntryResponse{
+			ID:        entry.ID,
+			CreatedAt: entry.CreatedAt,
+			ActorType: entry.ActorType,
+			ActorID:   entry.ActorID,
+			Action:    entry.Action,
+			StockID:   entry.StockID,
+			Ticker:    entry.Ticker,
+			Before:    before,
+			After:     after,
+		})
+	}
+
+	return responses, nil
+}
+
+func auditSnapshot(state json.RawMessage) (*StockResponse, error) {
+	if len(state) == 0 || string(state) == "null" {
+		return nil, nil
+	}
+
+	var stock core.Stock
+	if err := json.Unmarshal(state, &stock); err != nil {
+		return nil, err
+	}
+
+	response := newStockResponse(stock)
+	return &response, nil
+}
+
+// HealthResponse is the result of a health endpoint: the status of each
+// check and, in Status, the worst of them.
+type HealthResponse struct {
+	Status string                         `json:"status"`
+	Checks map[string]HealthCheckResponse `json:"checks"`
+}
+
+// HealthCheckResponse is the status of a single check, with the values it
+// looked at.
+type HealthCheckResponse struct {
+	Status     string     `json:"status"`
+	LastSyncAt *time.Time `json:"last_sync_at,omitempty"`
+	StaleAfter string     `json:"stale_after,omitempty"`
+	RowCount   *int64     `json:"row_count,omitempty"`
+}
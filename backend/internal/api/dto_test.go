@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"stockify/internal/core"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files under testdata/golden")
+
+// assertGolden compares the indented JSON body with testdata/golden/name.json,
+// rewriting the file instead when the tests run with -update.
+func assertGolden(t *testing.T, name string, body []byte) {
+	t.Helper()
+
+	var indented bytes.Buffer
+	require.NoError(t, json.Indent(&indented, body, "", "  "))
+	indented.WriteByte('\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, indented.Bytes(), 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run the tests with -update to create the golden file")
+	assert.Equal(t, string(want), indented.String(), "JSON contract changed; if intended, rerun with -update and review the diff")
+}
+
+// goldenStocks are the events served to the golden tests: two tickers with
+// events on consecutive days, and a deleted one.
+func goldenStocks() []core.Stock {
+	at := func(day int) time.Time { return time.Date(2024, 3, day, 12, 0, 0, 0, time.UTC) }
+	model := func(day int) gorm.Model {
+		return gorm.Model{CreatedAt: at(day).Add(time.Hour), UpdatedAt: at(day).Add(time.Hour)}
+	}
+	ratingFrom, targetFrom, targetTo := "Hold", 180.0, 215.5
+	deleted := model(4)
+	deleted.DeletedAt = gorm.DeletedAt{Time: at(5), Valid: true}
+
+	return []core.Stock{
+		{Model: model(1), Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker A", Action: "upgraded by", RatingFrom: &ratingFrom, RatingTo: "Buy", TargetFrom: &targetFrom, TargetTo: &targetTo, Time: at(1)},
+		{Model: model(2), Ticker: "MSFT", Company: "Microsoft Corp.", Brokerage: "Broker B", Action: "initiated by", RatingTo: "Outperform", Time: at(2)},
+		{Model: model(3), Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker C", Action: "reiterated by", RatingTo: "Neutral", Time: at(3)},
+		{Model: deleted, Ticker: "NVDA", Company: "NVIDIA Corp.", Brokerage: "Broker A", Action: "downgraded by", RatingTo: "Sell", Time: at(4)},
+	}
+}
+
+func TestResponses_Golden(t *testing.T) {
+	router := newTestRouter(t, newAdminTestConfig(), goldenStocks())
+
+	cases := []struct {
+		name           string
+		target         string
+		acceptLanguage string
+	}{
+		{"stocks", "/api/v1/stocks?sortBy=time&sortOrder=asc", ""},
+		{"stocks_latest", "/api/v1/stocks?view=latest&sortBy=ticker&sortOrder=asc", ""},
+		{"stocks_fields", "/api/v1/stocks?fields=id,ticker,target_to&sortBy=time&sortOrder=asc&pageSize=2", ""},
+		{"stock", "/api/v1/stocks/AAPL", ""},
+		{"recommendations", "/api/v1/stocks/recommendations", ""},
+		{"recommendations_en", "/api/v1/stocks/recommendations?lang=en", ""},
+		{"deleted_stocks", "/api/v1/admin/stocks/deleted", ""},
+		{"problem", "/api/v1/stocks?fields=price", ""},
+		{"problem_en", "/api/v1/stocks?fields=price", "en-US,en;q=0.9,es;q=0.5"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			req.Header.Set("X-API-Key", "key")
+			req.Header.Set(middleware.RequestIDHeader, "golden")
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assertGolden(t, tc.name, rec.Body.Bytes())
+		})
+	}
+}
+
+func TestAuditEntryResponses_Golden(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	stock := core.Stock{Model: gorm.Model{ID: 7, CreatedAt: created, UpdatedAt: created}, Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker A", Action: "upgraded by", RatingTo: "Buy", Time: created}
+	snapshot, err := json.Marshal(stock)
+	require.NoError(t, err)
+
+	responses, err := newAuditEntryResponses([]core.AuditEntry{
+		{ID: 1, CreatedAt: created, ActorType: core.ActorTypeSync, ActorID: "run-1", Action: core.AuditActionCreate, StockID: 7, Ticker: "AAPL", After: snapshot},
+		{ID: 2, CreatedAt: created.Add(time.Hour), ActorType: core.ActorTypeAPIKey, ActorID: "alice", Action: core.AuditActionDelete, StockID: 7, Ticker: "AAPL", Before: snapshot, After: json.RawMessage("null")},
+	})
+	require.NoError(t, err)
+
+	body, err := json.Marshal(responses)
+	require.NoError(t, err)
+	assertGolden(t, "audit_entries", body)
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var exportCSVHeader = []string{"id", "ticker", "company", "brokerage", "action", "rating_from", "rating_to", "target_from", "target_to", "time"}
+
+// stockEncoder writes exported stocks in one output format.
+type stockEncoder interface {
+	begin() error
+	encode(stock core.Stock) error
+	end() error
+}
+
+type csvStockEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvStockEncoder) begin() error {
+	return e.w.Write(exportCSVHeader)
+}
+
+func (e *csvStockEncoder) encode(stock core.Stock) error {
+	ratingFrom := ""
+	if stock.RatingFrom != nil {
+		ratingFrom = *stock.RatingFrom
+	}
+
+	return e.w.Write([]string{
+		strconv.FormatUint(uint64(stock.ID), 10),
+		escapeFormula(stock.Ticker),
+		escapeFormula(stock.Company),
+		escapeFormula(stock.Brokerage),
+		escapeFormula(stock.Action),
+		escapeFormula(ratingFrom),
+		escapeFormula(stock.RatingTo),
+		formatOptionalFloat(stock.TargetFrom),
+		formatOptionalFloat(stock.TargetTo),
+		stock.Time.UTC().Format(time.RFC3339),
+	})
+}
+
+func (e *csvStockEncoder) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// escapeFormula prefixes with a quote the text values that spreadsheets would
+// otherwise evaluate as formulas, since company and brokerage names come from
+// the external API and imports. Numeric columns are formatted by us and left
+// as they are.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 2, 64)
+}
+
+type ndjsonStockEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonStockEncoder) begin() error {
+	return nil
+}
+
+func (e *ndjsonStockEncoder) encode(stock core.Stock) error {
+	return e.enc.Encode(newStockResponse(stock))
+}
+
+func (e *ndjsonStockEncoder) end() error {
+	return nil
+}
+
+// ExportStocks streams every stock matching the list endpoint's search,
+// sorting and include_archived parameters as CSV or NDJSON. Rows are written
+// as they are read from the store, without pagination.
+func (h *StockHandler) ExportStocks(w http.ResponseWriter, r *http.Request) {
+	params := parseListParams(r)
+
+	var contentType, filename string
+	var encoder stockEncoder
+
+	switch r.URL.Query().Get("format") {
+	case "", "csv":
+		contentType, filename = "text/csv; charset=utf-8", "stocks.csv"
+		encoder = &csvStockEncoder{w: csv.NewWriter(w)}
+	case "ndjson":
+		contentType, filename = "application/x-ndjson", "stocks.ndjson"
+		encoder = &ndjsonStockEncoder{enc: json.NewEncoder(w)}
+	default:
+		respondWithValidationError(w, r, i18n.MsgInvalidFormat, newFieldError("format", i18n.MsgFieldAllowedValues, i18n.Params{"values": "csv, ndjson"}))
+		return
+	}
+
+	started := false
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+		return encoder.begin()
+	}
+
+	rc := http.NewResponseController(w)
+	err := h.stockService.ExportStocks(r.Context(), params, func(stock core.Stock) error {
+		extendWriteDeadline(rc)
+		if err := start(); err != nil {
+			return err
+		}
+		return encoder.encode(stock)
+	})
+
+	if !started && respondWithInvalidSort(w, r, err) {
+		return
+	}
+	if err != nil && !started {
+		slog.ErrorContext(r.Context(), "Error en ExportStocks service", "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgExportFailed)
+		return
+	}
+	if err != nil {
+		// The status line is already sent, so abort the connection instead
+		// of ending the body cleanly; otherwise the client could not tell
+		// the download is incomplete.
+		slog.ErrorContext(r.Context(), "Error en ExportStocks service tras iniciar la respuesta", "error", err)
+		panic(http.ErrAbortHandler)
+	}
+
+	extendWriteDeadline(rc)
+	if err := start(); err != nil {
+		slog.WarnContext(r.Context(), "Error escribiendo la exportación", "error", err)
+		return
+	}
+	if err := encoder.end(); err != nil {
+		slog.WarnContext(r.Context(), "Error escribiendo la exportación", "error", err)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/core"
+	"stockify/internal/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportHandler() *StockHandler {
+	targetTo := 215.5
+	stocks := []core.Stock{
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker A", Action: "target raised by", RatingTo: "Buy", TargetTo: &targetTo, Time: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{Ticker: "MSFT", Company: "Microsoft, Corp.", Brokerage: "Broker B", Action: "upgraded by", RatingTo: "Outperform", Time: time.Date(2025, 5, 2, 12, 0, 0, 0, time.UTC)},
+	}
+
+	return newTestStockHandler(store.NewMemoryStore(stocks), 0)
+}
+
+func TestExportStocks_CSV(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newExportHandler().ExportStocks(rec, httptest.NewRequest(http.MethodGet, "/api/stocks/export?format=csv&sortBy=ticker&sortOrder=asc&pageSize=1", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "stocks.csv")
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3, "header plus every row, ignoring pageSize")
+	assert.Equal(t, exportCSVHeader, records[0])
+	assert.Equal(t, []string{"1", "AAPL", "Apple Inc.", "Broker A", "target raised by", "", "Buy", "", "215.50", "2025-05-01T12:00:00Z"}, records[1])
+	assert.Equal(t, "Microsoft, Corp.", records[2][2])
+}
+
+func TestExportStocks_CSVEscapesFormulas(t *testing.T) {
+	stocks := []core.Stock{
+		{Ticker: "EVIL", Company: "=HYPERLINK(\"http://x\")", Brokerage: "@SUM(A1)", Action: "-2+3", RatingTo: "+1", Time: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	handler := newTestStockHandler(store.NewMemoryStore(stocks), 0)
+
+	rec := httptest.NewRecorder()
+	handler.ExportStocks(rec, httptest.NewRequest(http.MethodGet, "/api/stocks/export?format=csv", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"EVIL", "'=HYPERLINK(\"http://x\")", "'@SUM(A1)", "'-2+3", "", "'+1"}, records[1][1:7])
+}
+
+func TestExportStocks_NDJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newExportHandler().ExportStocks(rec, httptest.NewRequest(http.MethodGet, "/api/stocks/export?format=ndjson&search=micro", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	var lines []core.Stock
+	scanner := bufio.NewScanner(strings.NewReader(rec.Body.String()))
+	for scanner.Scan() {
+		var stock core.Stock
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &stock))
+		lines = append(lines, stock)
+	}
+	require.Len(t, lines, 1)
+	assert.Equal(t, "MSFT", lines[0].Ticker)
+}
+
+func TestExportStocks_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+	}{
+		{"unknown format", "/api/stocks/export?format=xlsx", http.StatusBadRequest},
+		{"unknown sort column", "/api/stocks/export?sortBy=missing", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newExportHandler().ExportStocks(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"stockify/internal/i18n"
+	"strings"
+)
+
+// Members of the stock representations that the fields query parameter may
+// select, in the order they are serialized.
+var (
+	stockFields       = jsonFieldNames(reflect.TypeOf(StockResponse{}))
+	latestStockFields = jsonFieldNames(reflect.TypeOf(LatestStockResponse{}))
+)
+
+// jsonFieldNames lists the JSON member names of the struct type t, including
+// those of embedded structs, as encoding/json would serialize them.
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			names = append(names, jsonFieldNames(field.Type)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// parseFields reads the comma-separated fields query parameter, matching each
+// name case-insensitively against allowed. It returns nil when the parameter
+// is empty, meaning every member is kept, and a FieldError listing the
+// allowed names if any field is unknown.
+func parseFields(value string, allowed []string) ([]string, *FieldError) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var fields, unknown []string
+	seen := make(map[string]bool)
+
+	for _, requested := range strings.Split(value, ",") {
+		requested = strings.TrimSpace(requested)
+		if requested == "" {
+			continue
+		}
+
+		match := ""
+		for _, name := range allowed {
+			if strings.EqualFold(name, requested) {
+				match = name
+				break
+			}
+		}
+
+		switch {
+		case match == "":
+			unknown = append(unknown, requested)
+		case !seen[match]:
+			seen[match] = true
+			fields = append(fields, match)
+		}
+	}
+
+	if len(unknown) > 0 {
+		fieldErr := newFieldError("fields", i18n.MsgFieldUnknownFields, i18n.Params{
+			"unknown": strings.Join(unknown, ", "),
+			"allowed": strings.Join(allowed, ", "),
+		})
+		return nil, &fieldErr
+	}
+
+	return fields, nil
+}
+
+// selectFields returns payload, an object or an array of objects, keeping
+// only the given members of each object, in the order of the representation.
+// Members left out of the JSON encoding, such as empty omitempty fields, stay
+// absent.
+func selectFields(payload interface{}, fields []string) (interface{}, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encoded) > 0 && encoded[0] == '[' {
+		var objects []json.RawMessage
+		if err := json.Unmarshal(encoded, &objects); err != nil {
+			return nil, err
+		}
+
+		selected := make([]orderedObject, 0, len(objects))
+		for _, object := range objects {
+			kept, err := keepMembers(object, fields)
+			if err != nil {
+				return nil, err
+			}
+			selected = append(selected, kept)
+		}
+		return selected, nil
+	}
+
+	return keepMembers(encoded, fields)
+}
+
+// keepMembers decodes the JSON object data, keeping only the members named in
+// fields.
+func keepMembers(data []byte, fields []string) (orderedObject, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	kept := make(orderedObject, 0, len(fields))
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := token.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		if slices.Contains(fields, name) {
+			kept = append(kept, objectMember{name: name, value: value})
+		}
+	}
+
+	return kept, nil
+}
+
+// orderedObject is a JSON object whose members are encoded in order, unlike a
+// map, whose keys encoding/json sorts.
+type orderedObject []objectMember
+
+type objectMember struct {
+	name  string
+	value json.RawMessage
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(member.name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(member.value)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
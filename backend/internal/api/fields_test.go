@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/core"
+	"stockify/internal/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFieldsHandler() *StockHandler {
+	targetTo := 215.5
+	return newTestStockHandler(store.NewMemoryStore([]core.Stock{
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker A", Action: "upgraded by", RatingTo: "Buy", TargetTo: &targetTo, Time: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker B", Action: "initiated by", RatingTo: "Hold", Time: time.Date(2025, 5, 2, 12, 0, 0, 0, time.UTC)},
+	}), 0)
+}
+
+func TestGetStocks_Fields(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newFieldsHandler().GetStocks(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stocks?fields=ticker,RATING_TO,target_to,ticker&sortBy=time&sortOrder=asc", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		Stocks     []map[string]any `json:"stocks"`
+		TotalItems int              `json:"totalItems"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+
+	assert.Equal(t, 2, body.TotalItems)
+	assert.Equal(t, []map[string]any{
+		{"ticker": "AAPL", "rating_to": "Buy", "target_to": 215.5},
+		{"ticker": "AAPL", "rating_to": "Hold", "target_to": nil},
+	}, body.Stocks)
+}
+
+func TestGetStocks_FieldsLatestView(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newFieldsHandler().GetStocks(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stocks?view=latest&fields=id,event_count", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"stocks": [{"id": 2, "event_count": 2}], "totalItems": 1, "page": 1, "pageSize": 10, "totalPages": 1}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	newFieldsHandler().GetStocks(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stocks?fields=event_count", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "event_count only exists in the latest view")
+}
+
+func TestGetStocks_UnknownFields(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newFieldsHandler().GetStocks(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stocks?fields=ticker,price,volume", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, ErrCodeInvalidParameter, problem.Code)
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "fields", problem.Errors[0].Field)
+	assert.Equal(t, "campos desconocidos: price, volume; campos permitidos: id, ticker, company, brokerage, action, rating_from, rating_to, target_from, target_to, time, created_at, updated_at, deleted_at", problem.Errors[0].Message)
+}
+
+func TestGetStockByTicker_Fields(t *testing.T) {
+	handler := newFieldsHandler()
+
+	get := func(target string) *httptest.ResponseRecorder {
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("ticker", "AAPL")
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+
+		rec := httptest.NewRecorder()
+		handler.GetStockByTicker(rec, req)
+		return rec
+	}
+
+	rec := get("/api/v1/stocks/AAPL?fields=company,time")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"company": "Apple Inc.", "time": "2025-05-01T12:00:00Z"}`, rec.Body.String())
+
+	rec = get("/api/v1/stocks/AAPL?fields=time,ticker,company")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"ticker":"AAPL","company":"Apple Inc.","time":"2025-05-01T12:00:00Z"}`, strings.TrimSpace(rec.Body.String()),
+		"members keep the order of the response DTO")
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/stocks/AAPL?fields=nope").Code)
+}
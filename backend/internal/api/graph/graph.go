@@ -0,0 +1,59 @@
+// Package graph serves a GraphQL view of the stock domain built on the same
+// services as the REST API.
+package graph
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"stockify/internal/i18n"
+	"stockify/internal/services"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// maxPageSize bounds the pageSize argument of paginated queries.
+const maxPageSize = 100
+
+// maxBodyBytes bounds the size of the request body, query and variables
+// included.
+const maxBodyBytes = 1 << 20
+
+// NewHandler returns an HTTP handler executing GraphQL queries sent as JSON
+// POST bodies of the form {"query", "operationName", "variables"}.
+func NewHandler(stockService *services.StockService, recommendationService *services.RecommendationService) http.Handler {
+	resolver := &Resolver{stockService: stockService, recommendationService: recommendationService}
+	return limitBody(&relay.Handler{Schema: graphql.MustParseSchema(schema, resolver, graphql.MaxDepth(10))})
+}
+
+// limitBody answers 413 with a GraphQL error, worded in the language of the
+// request, when the body exceeds maxBodyBytes, as relay.Handler would read
+// it whole.
+func limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			message := i18n.T(i18n.FromContext(r.Context()), i18n.MsgQueryTooLarge, nil)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(map[string]any{"errors": []map[string]string{{"message": message}}})
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,278 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore counts the batched event lookups to detect N+1 queries.
+type countingStore struct {
+	store.StockStoreInterface
+	tickerQueries int
+}
+
+func (s *countingStore) GetStocksByTickers(ctx context.Context, tickers []string, includeArchived bool) ([]core.Stock, error) {
+	s.tickerQueries++
+	return s.StockStoreInterface.GetStocksByTickers(ctx, tickers, includeArchived)
+}
+
+func newTestStore() *countingStore {
+	day := func(d int) time.Time { return time.Date(2025, 5, d, 12, 0, 0, 0, time.UTC) }
+	target := func(v float64) *float64 { return &v }
+
+	stocks := []core.Stock{
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker A", Action: "upgraded by", RatingTo: "Buy", TargetTo: target(200), Time: day(1)},
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker B", Action: "target raised by", RatingTo: "Buy", TargetTo: target(220), Time: day(2)},
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker A", Action: "downgraded by", RatingTo: "Hold", TargetTo: target(180), Time: day(3)},
+		{Ticker: "MSFT", Company: "Microsoft Corp.", Brokerage: "Broker B", Action: "initiated by", RatingTo: "Outperform", Time: day(4)},
+		{Ticker: "NVDA", Company: "NVIDIA Corp.", Brokerage: "Broker C", Action: "upgraded by", RatingTo: "Buy", TargetTo: target(150), Time: day(5)},
+	}
+
+	return &countingStore{StockStoreInterface: store.NewMemoryStore(stocks)}
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func execute(t *testing.T, s store.StockStoreInterface, query string, variables map[string]any) graphQLResponse {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(t, err)
+
+	handler := NewHandler(services.NewStockService(s), services.NewRecommendationService(s))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/graphql", strings.NewReader(string(body))))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response graphQLResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return response
+}
+
+func TestStocks_BatchesEventLookups(t *testing.T) {
+	s := newTestStore()
+
+	response := execute(t, s, `query {
+		stocks(sortBy: "ticker", sortOrder: "asc", pageSize: 10) {
+			totalItems
+			totalPages
+			items {
+				ticker
+				eventCount
+				events(limit: 1) { brokerage }
+				brokerages
+				consensus { brokerageCount averageTargetTo ratings { rating count } }
+			}
+		}
+	}`, nil)
+	require.Empty(t, response.Errors)
+
+	var data struct {
+		Stocks struct {
+			TotalItems int
+			TotalPages int
+			Items      []struct {
+				Ticker     string
+				EventCount int
+				Events     []struct{ Brokerage string }
+				Brokerages []string
+				Consensus  struct {
+					BrokerageCount  int
+					AverageTargetTo *float64
+					Ratings         []struct {
+						Rating string
+						Count  int
+					}
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(response.Data, &data))
+
+	assert.Equal(t, 1, s.tickerQueries, "events of the whole page must be loaded with one query")
+	assert.Equal(t, 3, data.Stocks.TotalItems)
+	assert.Equal(t, 1, data.Stocks.TotalPages)
+	require.Len(t, data.Stocks.Items, 3)
+
+	apple := data.Stocks.Items[0]
+	assert.Equal(t, "AAPL", apple.Ticker)
+	assert.Equal(t, 3, apple.EventCount)
+	require.Len(t, apple.Events, 1)
+	assert.Equal(t, "Broker A", apple.Events[0].Brokerage)
+	assert.Equal(t, []string{"Broker A", "Broker B"}, apple.Brokerages)
+	assert.Equal(t, 2, apple.Consensus.BrokerageCount)
+	require.NotNil(t, apple.Consensus.AverageTargetTo)
+	assert.InDelta(t, 200, *apple.Consensus.AverageTargetTo, 0.001, "latest targets of Broker A (180) and Broker B (220)")
+	assert.Len(t, apple.Consensus.Ratings, 2)
+}
+
+func TestStocks_IncludeArchivedEvents(t *testing.T) {
+	s := newTestStore()
+	archived, err := s.StockStoreInterface.(*store.MemoryStore).ArchiveStocks(context.Background(), time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), archived)
+
+	query := `query($includeArchived: Boolean) {
+		stocks(search: "AAPL", includeArchived: $includeArchived) {
+			items { eventCount events { brokerage } brokerages }
+		}
+	}`
+
+	for _, includeArchived := range []bool{false, true} {
+		response := execute(t, s, query, map[string]any{"includeArchived": includeArchived})
+		require.Empty(t, response.Errors)
+
+		var data struct {
+			Stocks struct {
+				Items []struct {
+					EventCount int
+					Events     []struct{ Brokerage string }
+					Brokerages []string
+				}
+			}
+		}
+		require.NoError(t, json.Unmarshal(response.Data, &data))
+		require.Len(t, data.Stocks.Items, 1)
+
+		apple := data.Stocks.Items[0]
+		if includeArchived {
+			assert.Equal(t, 3, apple.EventCount)
+			assert.Len(t, apple.Events, 3, "the events of a stock include the archived ones")
+		} else {
+			assert.Equal(t, 2, apple.EventCount)
+			assert.Len(t, apple.Events, 2)
+		}
+		assert.Equal(t, []string{"Broker A", "Broker B"}, apple.Brokerages)
+	}
+}
+
+func TestRequestBodyTooLarge(t *testing.T) {
+	s := newTestStore()
+	handler := NewHandler(services.NewStockService(s), services.NewRecommendationService(s))
+
+	body := `{"query": "query { events { totalItems } }", "variables": {"padding": "` + strings.Repeat("x", maxBodyBytes) + `"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/graphql", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(i18n.WithLang(req.Context(), i18n.English)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	var response graphQLResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "The GraphQL query exceeds the maximum allowed size", response.Errors[0].Message)
+}
+
+func TestStock(t *testing.T) {
+	response := execute(t, newTestStore(), `query($ticker: String!) {
+		stock(ticker: $ticker) { ticker company latestEvent { ratingTo time } }
+		missing: stock(ticker: "ZZZZ") { ticker }
+	}`, map[string]any{"ticker": "aapl"})
+	require.Empty(t, response.Errors)
+
+	assert.JSONEq(t, `{
+		"stock": {"ticker": "AAPL", "company": "Apple Inc.", "latestEvent": {"ratingTo": "Hold", "time": "2025-05-03T12:00:00Z"}},
+		"missing": null
+	}`, string(response.Data))
+}
+
+func TestEventsAndBrokerages(t *testing.T) {
+	response := execute(t, newTestStore(), `query {
+		events(search: "apple", sortBy: "time", sortOrder: "asc", page: 2, pageSize: 2) {
+			totalItems page totalPages items { ticker brokerage }
+		}
+		brokerages(search: "broker") { name eventCount }
+	}`, nil)
+	require.Empty(t, response.Errors)
+
+	assert.JSONEq(t, `{
+		"events": {"totalItems": 3, "page": 2, "totalPages": 2, "items": [{"ticker": "AAPL", "brokerage": "Broker A"}]},
+		"brokerages": [
+			{"name": "Broker A", "eventCount": 2},
+			{"name": "Broker B", "eventCount": 2},
+			{"name": "Broker C", "eventCount": 1}
+		]
+	}`, string(response.Data))
+}
+
+func TestRecommendations(t *testing.T) {
+	s := newTestStore()
+
+	response := execute(t, s, `query {
+		recommendations(limit: 2) { score stock { ticker eventCount } event { ticker } reasons { type details params { name value } } }
+	}`, nil)
+	require.Empty(t, response.Errors)
+
+	var data struct {
+		Recommendations []struct {
+			Score float64
+			Stock struct {
+				Ticker     string
+				EventCount int
+			}
+			Event   struct{ Ticker string }
+			Reasons []struct {
+				Type, Details string
+				Params        []struct{ Name, Value string }
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(response.Data, &data))
+
+	require.Len(t, data.Recommendations, 2)
+	assert.Equal(t, 1, s.tickerQueries)
+	for _, recommendation := range data.Recommendations {
+		assert.Equal(t, recommendation.Event.Ticker, recommendation.Stock.Ticker)
+		assert.NotZero(t, recommendation.Stock.EventCount)
+		assert.NotEmpty(t, recommendation.Reasons)
+		for _, reason := range recommendation.Reasons {
+			assert.NotEmpty(t, reason.Params, "reason %s has no params", reason.Type)
+		}
+	}
+}
+
+func TestInvalidPagination(t *testing.T) {
+	response := execute(t, newTestStore(), `query { events(pageSize: 1000) { totalItems } }`, nil)
+
+	require.Len(t, response.Errors, 1)
+	assert.Contains(t, response.Errors[0].Message, "Parámetro pageSize inválido: debe estar entre 1 y 100")
+}
+
+func TestInvalidSort(t *testing.T) {
+	response := execute(t, newTestStore(), `query { events(sortBy: "(SELECT 1)") { totalItems } }`, nil)
+
+	require.Len(t, response.Errors, 1)
+	assert.Contains(t, response.Errors[0].Message, "Parámetro sortBy inválido: valores permitidos: id, created_at")
+}
+
+type failingStore struct {
+	store.StockStoreInterface
+}
+
+func (failingStore) GetStocks(ctx context.Context, params store.GetStocksParams) ([]core.Stock, int64, error) {
+	return nil, 0, errors.New(`pq: syntax error at or near "FROM stocks"`)
+}
+
+func TestStoreErrorsAreNotExposed(t *testing.T) {
+	response := execute(t, failingStore{newTestStore()}, `query { events { totalItems } }`, nil)
+
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "Falló la obtención de acciones", response.Errors[0].Message)
+}
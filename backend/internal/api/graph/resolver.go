@@ -0,0 +1,460 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// Resolver is the root of the GraphQL schema.
+type Resolver struct {
+	stockService          *services.StockService
+	recommendationService *services.RecommendationService
+}
+
+type listArgs struct {
+	Search          *string
+	SortBy          *string
+	SortOrder       *string
+	Page            int32
+	PageSize        int32
+	IncludeArchived bool
+}
+
+// params validates the pagination of args, reporting errors in the language
+// of ctx.
+func (args listArgs) params(ctx context.Context) (store.GetStocksParams, error) {
+	params := store.GetStocksParams{
+		Page:            int(args.Page),
+		PageSize:        int(args.PageSize),
+		IncludeArchived: args.IncludeArchived,
+	}
+
+	if args.Search != nil {
+		params.Search = *args.Search
+	}
+	if args.SortBy != nil {
+		params.SortBy = *args.SortBy
+	}
+	if args.SortOrder != nil {
+		params.SortOrder = *args.SortOrder
+	}
+
+	lang := i18n.FromContext(ctx)
+	if params.Page < 1 {
+		return params, invalidArgument(lang, i18n.MsgInvalidPage, i18n.MsgFieldPositiveInteger, nil)
+	}
+	if params.PageSize < 1 || params.PageSize > maxPageSize {
+		return params, invalidArgument(lang, i18n.MsgInvalidPageSize, i18n.MsgFieldRange, i18n.Params{"min": 1, "max": maxPageSize})
+	}
+
+	return params, nil
+}
+
+func (r *Resolver) Stocks(ctx context.Context, args listArgs) (*stockPageResolver, error) {
+	params, err := args.params(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, totalItems, err := r.stockService.ListLatestStocks(ctx, params)
+	if err != nil {
+		return nil, serviceError(ctx, "ListLatestStocks", i18n.MsgListStocksFailed, err)
+	}
+
+	tickers := make([]string, 0, len(latest))
+	for _, stock := range latest {
+		tickers = append(tickers, stock.Ticker)
+	}
+	batch := r.newEventBatch(tickers, params.IncludeArchived)
+
+	items := make([]*stockResolver, 0, len(latest))
+	for _, stock := range latest {
+		items = append(items, &stockResolver{latest: stock.Stock, eventCount: stock.EventCount, batch: batch})
+	}
+
+	return &stockPageResolver{items: items, page: newPageInfo(totalItems, params)}, nil
+}
+
+func (r *Resolver) Stock(ctx context.Context, args struct{ Ticker string }) (*stockResolver, error) {
+	batch := r.newEventBatch([]string{args.Ticker}, false)
+
+	events, err := batch.eventsOf(ctx, args.Ticker)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+
+	return &stockResolver{latest: events[0], eventCount: int64(len(events)), batch: batch}, nil
+}
+
+func (r *Resolver) Events(ctx context.Context, args listArgs) (*eventPageResolver, error) {
+	params, err := args.params(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stocks, totalItems, err := r.stockService.ListStocks(ctx, params)
+	if err != nil {
+		return nil, serviceError(ctx, "ListStocks", i18n.MsgListStocksFailed, err)
+	}
+
+	return &eventPageResolver{items: eventResolvers(stocks), page: newPageInfo(totalItems, params)}, nil
+}
+
+func (r *Resolver) Brokerages(ctx context.Context, args struct{ Search *string }) ([]*brokerageResolver, error) {
+	search := ""
+	if args.Search != nil {
+		search = *args.Search
+	}
+
+	brokerages, err := r.stockService.ListBrokerages(ctx, search)
+	if err != nil {
+		return nil, serviceError(ctx, "ListBrokerages", i18n.MsgListBrokeragesFailed, err)
+	}
+
+	resolvers := make([]*brokerageResolver, 0, len(brokerages))
+	for _, brokerage := range brokerages {
+		resolvers = append(resolvers, &brokerageResolver{brokerage})
+	}
+
+	return resolvers, nil
+}
+
+func (r *Resolver) Recommendations(ctx context.Context, args struct{ Limit *int32 }) ([]*recommendationResolver, error) {
+	recommendations, err := r.recommendationService.GetRecommendations(ctx)
+	if err != nil {
+		return nil, serviceError(ctx, "GetRecommendations", i18n.MsgRecommendationsFailed, err)
+	}
+
+	if args.Limit != nil && *args.Limit >= 0 && int(*args.Limit) < len(recommendations) {
+		recommendations = recommendations[:*args.Limit]
+	}
+
+	tickers := make([]string, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		tickers = append(tickers, recommendation.Ticker)
+	}
+	batch := r.newEventBatch(tickers, false)
+
+	resolvers := make([]*recommendationResolver, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		resolvers = append(resolvers, &recommendationResolver{recommendation: recommendation, batch: batch})
+	}
+
+	return resolvers, nil
+}
+
+// serviceError logs err and returns in its place the message of key, worded in
+// the language of the request, so that the text of store errors, which may
+// include SQL, never reaches clients. Invalid sorting is the client's fault
+// and is reported as such.
+func serviceError(ctx context.Context, operation string, key i18n.Key, err error) error {
+	lang := i18n.FromContext(ctx)
+
+	var sortErr *store.InvalidSortError
+	if errors.As(err, &sortErr) {
+		message := i18n.MsgInvalidSortBy
+		if sortErr.Field == "SortOrder" {
+			message = i18n.MsgInvalidSortOrder
+		}
+		return invalidArgument(lang, message, i18n.MsgFieldAllowedValues, i18n.Params{"values": strings.Join(sortErr.Allowed, ", ")})
+	}
+
+	slog.ErrorContext(ctx, "Error en servicio desde GraphQL", "operation", operation, "error", err)
+	return errors.New(i18n.T(lang, key, nil))
+}
+
+// invalidArgument rejects an argument with the message key followed by the
+// reason, both worded in lang, e.g. "Parámetro sortBy inválido: valores
+// permitidos: ...".
+func invalidArgument(lang i18n.Lang, message, reason i18n.Key, params i18n.Params) error {
+	return fmt.Errorf("%s: %s", i18n.T(lang, message, nil), i18n.T(lang, reason, params))
+}
+
+// eventBatch loads the events of every stock in a result the first time any
+// of them is needed, with a single query. Resolving events, brokerages or
+// consensus for a page of stocks therefore costs one query instead of one
+// per stock. Archived events are loaded too if includeArchived is set.
+type eventBatch struct {
+	stockService    *services.StockService
+	tickers         []string
+	includeArchived bool
+
+	once     sync.Once
+	byTicker map[string][]core.Stock
+	err      error
+}
+
+func (r *Resolver) newEventBatch(tickers []string, includeArchived bool) *eventBatch {
+	return &eventBatch{stockService: r.stockService, tickers: tickers, includeArchived: includeArchived}
+}
+
+func (b *eventBatch) eventsOf(ctx context.Context, ticker string) ([]core.Stock, error) {
+	b.once.Do(func() {
+		events, err := b.stockService.ListEventsByTickers(ctx, b.tickers, b.includeArchived)
+		if err != nil {
+			b.err = serviceError(ctx, "ListEventsByTickers", i18n.MsgGetStockFailed, err)
+		}
+
+		b.byTicker = make(map[string][]core.Stock)
+		for _, event := range events {
+			key := strings.ToUpper(event.Ticker)
+			b.byTicker[key] = append(b.byTicker[key], event)
+		}
+	})
+
+	return b.byTicker[strings.ToUpper(ticker)], b.err
+}
+
+type stockResolver struct {
+	latest     core.Stock
+	eventCount int64
+	batch      *eventBatch
+}
+
+func (s *stockResolver) Ticker() string  { return s.latest.Ticker }
+func (s *stockResolver) Company() string { return s.latest.Company }
+func (s *stockResolver) EventCount() int32 {
+	return int32(s.eventCount)
+}
+func (s *stockResolver) LatestEvent() *eventResolver {
+	return &eventResolver{s.latest}
+}
+
+func (s *stockResolver) Events(ctx context.Context, args struct{ Limit *int32 }) ([]*eventResolver, error) {
+	events, err := s.batch.eventsOf(ctx, s.latest.Ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Limit != nil && *args.Limit >= 0 && int(*args.Limit) < len(events) {
+		events = events[:*args.Limit]
+	}
+
+	return eventResolvers(events), nil
+}
+
+func (s *stockResolver) Brokerages(ctx context.Context) ([]string, error) {
+	events, err := s.batch.eventsOf(ctx, s.latest.Ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	brokerages := []string{}
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if !seen[event.Brokerage] {
+			seen[event.Brokerage] = true
+			brokerages = append(brokerages, event.Brokerage)
+		}
+	}
+	sort.Strings(brokerages)
+
+	return brokerages, nil
+}
+
+func (s *stockResolver) Consensus(ctx context.Context) (*consensusResolver, error) {
+	events, err := s.batch.eventsOf(ctx, s.latest.Ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	// events are newest first, so the first one seen per brokerage is its
+	// current rating.
+	counts := make(map[string]int32)
+	seen := make(map[string]bool)
+	var targetSum float64
+	var targetCount int
+
+	for _, event := range events {
+		if seen[event.Brokerage] {
+			continue
+		}
+		seen[event.Brokerage] = true
+
+		counts[event.RatingTo]++
+		if event.TargetTo != nil {
+			targetSum += *event.TargetTo
+			targetCount++
+		}
+	}
+
+	consensus := &consensusResolver{ratings: []*ratingCountResolver{}, brokerageCount: int32(len(seen))}
+	for rating, count := range counts {
+		consensus.ratings = append(consensus.ratings, &ratingCountResolver{rating: rating, count: count})
+	}
+	sort.Slice(consensus.ratings, func(i, j int) bool {
+		if consensus.ratings[i].count != consensus.ratings[j].count {
+			return consensus.ratings[i].count > consensus.ratings[j].count
+		}
+		return consensus.ratings[i].rating < consensus.ratings[j].rating
+	})
+	if targetCount > 0 {
+		average := targetSum / float64(targetCount)
+		consensus.averageTargetTo = &average
+	}
+
+	return consensus, nil
+}
+
+type eventResolver struct {
+	stock core.Stock
+}
+
+func eventResolvers(stocks []core.Stock) []*eventResolver {
+	resolvers := make([]*eventResolver, 0, len(stocks))
+	for _, stock := range stocks {
+		resolvers = append(resolvers, &eventResolver{stock})
+	}
+	return resolvers
+}
+
+func (e *eventResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(e.stock.ID), 10))
+}
+func (e *eventResolver) Ticker() string       { return e.stock.Ticker }
+func (e *eventResolver) Company() string      { return e.stock.Company }
+func (e *eventResolver) Brokerage() string    { return e.stock.Brokerage }
+func (e *eventResolver) Action() string       { return e.stock.Action }
+func (e *eventResolver) RatingFrom() *string  { return e.stock.RatingFrom }
+func (e *eventResolver) RatingTo() string     { return e.stock.RatingTo }
+func (e *eventResolver) TargetFrom() *float64 { return e.stock.TargetFrom }
+func (e *eventResolver) TargetTo() *float64   { return e.stock.TargetTo }
+func (e *eventResolver) Time() string         { return e.stock.Time.UTC().Format(time.RFC3339) }
+
+type consensusResolver struct {
+	ratings         []*ratingCountResolver
+	averageTargetTo *float64
+	brokerageCount  int32
+}
+
+func (c *consensusResolver) Ratings() []*ratingCountResolver { return c.ratings }
+func (c *consensusResolver) AverageTargetTo() *float64       { return c.averageTargetTo }
+func (c *consensusResolver) BrokerageCount() int32           { return c.brokerageCount }
+
+type ratingCountResolver struct {
+	rating string
+	count  int32
+}
+
+func (r *ratingCountResolver) Rating() string { return r.rating }
+func (r *ratingCountResolver) Count() int32   { return r.count }
+
+type brokerageResolver struct {
+	brokerage store.BrokerageSummary
+}
+
+func (b *brokerageResolver) Name() string      { return b.brokerage.Name }
+func (b *brokerageResolver) EventCount() int32 { return int32(b.brokerage.EventCount) }
+
+type recommendationResolver struct {
+	recommendation services.RecommendedStock
+	batch          *eventBatch
+}
+
+func (r *recommendationResolver) Stock(ctx context.Context) (*stockResolver, error) {
+	events, err := r.batch.eventsOf(ctx, r.recommendation.Ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := &stockResolver{latest: r.recommendation.Stock, eventCount: int64(len(events)), batch: r.batch}
+	if len(events) > 0 {
+		stock.latest = events[0]
+	}
+
+	return stock, nil
+}
+
+func (r *recommendationResolver) Event() *eventResolver {
+	return &eventResolver{r.recommendation.Stock}
+}
+func (r *recommendationResolver) Score() float64 { return r.recommendation.Score }
+
+func (r *recommendationResolver) Reasons() []*reasonResolver {
+	resolvers := make([]*reasonResolver, 0, len(r.recommendation.Reasons))
+	for _, reason := range r.recommendation.Reasons {
+		resolvers = append(resolvers, &reasonResolver{reason})
+	}
+	return resolvers
+}
+
+type reasonResolver struct {
+	reason services.RecommendationReason
+}
+
+func (r *reasonResolver) Type() string { return string(r.reason.Type) }
+func (r *reasonResolver) Details(ctx context.Context) string {
+	return r.reason.Localize(i18n.FromContext(ctx))
+}
+
+// Params lists the values details is built from, sorted by name.
+func (r *reasonResolver) Params() []*reasonParamResolver {
+	params := r.reason.StringParams()
+	resolvers := make([]*reasonParamResolver, 0, len(params))
+	for name, value := range params {
+		resolvers = append(resolvers, &reasonParamResolver{name: name, value: value})
+	}
+	sort.Slice(resolvers, func(i, j int) bool { return resolvers[i].name < resolvers[j].name })
+	return resolvers
+}
+
+type reasonParamResolver struct {
+	name, value string
+}
+
+func (r *reasonParamResolver) Name() string  { return r.name }
+func (r *reasonParamResolver) Value() string { return r.value }
+
+// pageInfo holds the pagination metadata shared by every paginated type.
+type pageInfo struct {
+	totalItems int64
+	page       int
+	pageSize   int
+}
+
+func newPageInfo(totalItems int64, params store.GetStocksParams) pageInfo {
+	return pageInfo{totalItems: totalItems, page: params.Page, pageSize: params.PageSize}
+}
+
+func (p pageInfo) TotalItems() int32 { return int32(p.totalItems) }
+func (p pageInfo) Page() int32       { return int32(p.page) }
+func (p pageInfo) PageSize() int32   { return int32(p.pageSize) }
+func (p pageInfo) TotalPages() int32 {
+	return int32(math.Ceil(float64(p.totalItems) / float64(p.pageSize)))
+}
+
+type stockPageResolver struct {
+	items []*stockResolver
+	page  pageInfo
+}
+
+func (p *stockPageResolver) Items() []*stockResolver { return p.items }
+func (p *stockPageResolver) TotalItems() int32       { return p.page.TotalItems() }
+func (p *stockPageResolver) Page() int32             { return p.page.Page() }
+func (p *stockPageResolver) PageSize() int32         { return p.page.PageSize() }
+func (p *stockPageResolver) TotalPages() int32       { return p.page.TotalPages() }
+
+type eventPageResolver struct {
+	items []*eventResolver
+	page  pageInfo
+}
+
+func (p *eventPageResolver) Items() []*eventResolver { return p.items }
+func (p *eventPageResolver) TotalItems() int32       { return p.page.TotalItems() }
+func (p *eventPageResolver) Page() int32             { return p.page.Page() }
+func (p *eventPageResolver) PageSize() int32         { return p.page.PageSize() }
+func (p *eventPageResolver) TotalPages() int32       { return p.page.TotalPages() }
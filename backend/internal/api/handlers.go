@@ -2,12 +2,17 @@ package api
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"math"
 	"net/http"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
 	"stockify/internal/services"
 	"stockify/internal/store"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -15,22 +20,21 @@ import (
 type StockHandler struct {
 	stockService          *services.StockService
 	recommendationService *services.RecommendationService
+	cacheMaxAge           time.Duration
 }
 
-func NewStockHandler(ss *services.StockService, rs *services.RecommendationService) *StockHandler {
-	return &StockHandler{stockService: ss, recommendationService: rs}
+// NewStockHandler creates the public stock handlers. cacheMaxAge is the
+// max-age advertised to HTTP caches for list, detail and recommendation
+// responses.
+func NewStockHandler(ss *services.StockService, rs *services.RecommendationService, cacheMaxAge time.Duration) *StockHandler {
+	return &StockHandler{stockService: ss, recommendationService: rs, cacheMaxAge: cacheMaxAge}
 }
 
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
-}
-
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+func respondWithJSON(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("Error marshalling JSON: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(`{"error": "Error interno del servidor al generar JSON"}`))
+		slog.ErrorContext(r.Context(), "Error marshalling JSON", "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgInternalError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -38,7 +42,9 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(response)
 }
 
-func (h *StockHandler) GetStocks(w http.ResponseWriter, r *http.Request) {
+// parseListParams reads the search, sorting and pagination query parameters
+// shared by every stock listing endpoint.
+func parseListParams(r *http.Request) store.GetStocksParams {
 	queryParams := r.URL.Query()
 	page, _ := strconv.Atoi(queryParams.Get("page"))
 	pageSize, _ := strconv.Atoi(queryParams.Get("pageSize"))
@@ -50,65 +56,175 @@ func (h *StockHandler) GetStocks(w http.ResponseWriter, r *http.Request) {
 		pageSize = 10
 	}
 
-	params := store.GetStocksParams{
-		Search:    queryParams.Get("search"),
-		SortBy:    queryParams.Get("sortBy"),
-		SortOrder: queryParams.Get("sortOrder"),
-		Page:      page,
-		PageSize:  pageSize,
-	}
+	includeArchived, _ := strconv.ParseBool(queryParams.Get("include_archived"))
 
-	stocks, totalItems, err := h.stockService.ListStocks(params)
-	if err != nil {
-		log.Printf("Error en ListStocks service: %v", err)
-		respondWithError(w, http.StatusInternalServerError, "Falló la obtención de acciones")
-		return
+	return store.GetStocksParams{
+		Search:          queryParams.Get("search"),
+		SortBy:          queryParams.Get("sortBy"),
+		SortOrder:       queryParams.Get("sortOrder"),
+		Page:            page,
+		PageSize:        pageSize,
+		IncludeArchived: includeArchived,
 	}
+}
 
+// paginatedResponse wraps a page of items under key together with the
+// pagination metadata returned by every listing endpoint.
+func paginatedResponse(key string, items interface{}, totalItems int64, page, pageSize int) map[string]interface{} {
 	totalPages := 0
 	if pageSize > 0 {
 		totalPages = int(math.Ceil(float64(totalItems) / float64(pageSize)))
 	}
 
-	response := map[string]interface{}{
-		"stocks":     stocks,
+	return map[string]interface{}{
+		key:          items,
 		"totalItems": totalItems,
 		"page":       page,
 		"pageSize":   pageSize,
 		"totalPages": totalPages,
 	}
-	respondWithJSON(w, http.StatusOK, response)
+}
+
+// respondWithInvalidSort rejects the request with 400 if err is the store
+// refusing the sortBy or sortOrder parameter, and reports whether it did.
+func respondWithInvalidSort(w http.ResponseWriter, r *http.Request, err error) bool {
+	var sortErr *store.InvalidSortError
+	if !errors.As(err, &sortErr) {
+		return false
+	}
+
+	field, message := "sortBy", i18n.MsgInvalidSortBy
+	if sortErr.Field == "SortOrder" {
+		field, message = "sortOrder", i18n.MsgInvalidSortOrder
+	}
+	respondWithValidationError(w, r, message, newFieldError(field, i18n.MsgFieldAllowedValues, i18n.Params{"values": strings.Join(sortErr.Allowed, ", ")}))
+	return true
+}
+
+func respondWithPage(w http.ResponseWriter, r *http.Request, stocks interface{}, totalItems int64, params store.GetStocksParams) {
+	respondWithJSON(w, r, http.StatusOK, paginatedResponse("stocks", stocks, totalItems, params.Page, params.PageSize))
+}
+
+func (h *StockHandler) GetStocks(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	params := parseListParams(r)
+
+	view := queryParams.Get("view")
+	if view != "" && view != "all" && view != "latest" {
+		respondWithValidationError(w, r, i18n.MsgInvalidView, newFieldError("view", i18n.MsgFieldAllowedValues, i18n.Params{"values": "all, latest"}))
+		return
+	}
+
+	allowedFields := stockFields
+	if view == "latest" {
+		allowedFields = latestStockFields
+	}
+	fields, fieldErr := parseFields(queryParams.Get("fields"), allowedFields)
+	if fieldErr != nil {
+		respondWithValidationError(w, r, i18n.MsgInvalidFields, *fieldErr)
+		return
+	}
+
+	// The store validates the sorting too, but only once the conditional
+	// headers would have answered 304.
+	if respondWithInvalidSort(w, r, store.ValidateSort(params, view == "latest")) {
+		return
+	}
+
+	if h.checkNotModified(w, r, "") {
+		return
+	}
+
+	var stocks interface{}
+	var totalItems int64
+	var err error
+
+	if view == "latest" {
+		var latest []core.LatestStock
+		latest, totalItems, err = h.stockService.ListLatestStocks(r.Context(), params)
+		stocks = newLatestStockResponses(latest)
+	} else {
+		var events []core.Stock
+		events, totalItems, err = h.stockService.ListStocks(r.Context(), params)
+		stocks = newStockResponses(events)
+	}
+
+	if respondWithInvalidSort(w, r, err) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error en ListStocks service", "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgListStocksFailed)
+		return
+	}
+
+	if fields != nil {
+		if stocks, err = selectFields(stocks, fields); err != nil {
+			slog.ErrorContext(r.Context(), "Error seleccionando campos", "fields", fields, "error", err)
+			respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgListStocksFailed)
+			return
+		}
+	}
+
+	respondWithPage(w, r, stocks, totalItems, params)
 }
 
 func (h *StockHandler) GetStockByTicker(w http.ResponseWriter, r *http.Request) {
 	ticker := chi.URLParam(r, "ticker")
 	if ticker == "" {
-		respondWithError(w, http.StatusBadRequest, "Parámetro ticker es requerido")
+		respondWithValidationError(w, r, i18n.MsgTickerRequired, newFieldError("ticker", i18n.MsgFieldRequired, nil))
+		return
+	}
+
+	fields, fieldErr := parseFields(r.URL.Query().Get("fields"), stockFields)
+	if fieldErr != nil {
+		respondWithValidationError(w, r, i18n.MsgInvalidFields, *fieldErr)
 		return
 	}
 
-	stock, err := h.stockService.GetStockByTicker(ticker)
+	// The stock is looked up before evaluating the conditional headers, so
+	// that a ticker that does not exist answers 404 rather than 304.
+	includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived"))
+	stock, err := h.stockService.GetStockByTicker(r.Context(), ticker, includeArchived)
 	if err != nil {
-		log.Printf("Error en GetStockByTicker service para %s: %v", ticker, err)
-		respondWithError(w, http.StatusInternalServerError, "Falló la obtención del stock")
+		slog.ErrorContext(r.Context(), "Error en GetStockByTicker service", "ticker", ticker, "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgGetStockFailed)
 		return
 	}
 	if stock == nil {
-		respondWithError(w, http.StatusNotFound, "Stock no encontrado")
+		respondWithError(w, r, http.StatusNotFound, ErrCodeStockNotFound, i18n.MsgStockNotFound)
+		return
+	}
+
+	if h.checkNotModified(w, r, "") {
 		return
 	}
-	respondWithJSON(w, http.StatusOK, stock)
+
+	var payload interface{} = newStockResponse(*stock)
+	if fields != nil {
+		if payload, err = selectFields(payload, fields); err != nil {
+			slog.ErrorContext(r.Context(), "Error seleccionando campos", "fields", fields, "error", err)
+			respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgGetStockFailed)
+			return
+		}
+	}
+	respondWithJSON(w, r, http.StatusOK, payload)
 }
 
 func (h *StockHandler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
-	recommendations, err := h.recommendationService.GetRecommendations()
-	if err != nil {
-		log.Printf("Error en GetRecommendations service: %v", err)
-		respondWithError(w, http.StatusInternalServerError, "Falló la obtención de recomendaciones")
+	// Recommendations also weigh how recent each event is, so they change
+	// from one day to the next even when the data does not, and their
+	// reasons are worded in the language of the request.
+	lang := i18n.FromContext(r.Context())
+	if h.checkNotModified(w, r, time.Now().UTC().Format("20060102")+"-"+string(lang)) {
 		return
 	}
-	response := map[string]interface{}{
-		"recommendations": recommendations,
+
+	recommendations, err := h.recommendationService.GetRecommendations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error en GetRecommendations service", "error", err)
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgRecommendationsFailed)
+		return
 	}
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, newRecommendationsResponse(recommendations, lang))
 }
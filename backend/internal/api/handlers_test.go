@@ -0,0 +1,13 @@
+package api
+
+import (
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"time"
+)
+
+// newTestStockHandler builds a StockHandler over ss, sending maxAge in
+// Cache-Control.
+func newTestStockHandler(ss store.StockStoreInterface, maxAge time.Duration) *StockHandler {
+	return NewStockHandler(services.NewStockService(ss), services.NewRecommendationService(ss), maxAge)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"stockify/internal/services"
+)
+
+// Statuses of the health checks, from best to worst.
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+	HealthStatusDown     = "down"
+)
+
+var healthSeverity = map[string]int{HealthStatusOK: 0, HealthStatusDegraded: 1, HealthStatusDown: 2}
+
+type HealthHandler struct {
+	healthService *services.HealthService
+}
+
+func NewHealthHandler(hs *services.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: hs}
+}
+
+// respondWithHealth sends checks with their overall status, answering 503
+// when a check is down so that orchestrators take the instance out of
+// rotation. Degraded checks keep answering 200.
+func respondWithHealth(w http.ResponseWriter, r *http.Request, checks map[string]HealthCheckResponse) {
+	response := HealthResponse{Status: HealthStatusOK, Checks: checks}
+	for _, check := range checks {
+		if healthSeverity[check.Status] > healthSeverity[response.Status] {
+			response.Status = check.Status
+		}
+	}
+
+	status := http.StatusOK
+	if response.Status == HealthStatusDown {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, r, status, response)
+}
+
+// Live reports that the process is up and serving requests. It checks
+// nothing else, so a failing dependency never gets the server restarted.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	respondWithHealth(w, r, map[string]HealthCheckResponse{})
+}
+
+// Ready reports whether the server can answer requests, which needs the
+// database to respond.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	check := HealthCheckResponse{Status: HealthStatusOK}
+	if err := h.healthService.Ping(r.Context()); err != nil {
+		slog.WarnContext(r.Context(), "Health check: la base de datos no responde", "error", err)
+		check.Status = HealthStatusDown
+	}
+
+	respondWithHealth(w, r, map[string]HealthCheckResponse{"database": check})
+}
+
+// Data reports the last successful sync and the number of events, degraded
+// when the sync is older than the staleness threshold or there are no
+// events at all.
+func (h *HealthHandler) Data(w http.ResponseWriter, r *http.Request) {
+	health, err := h.healthService.DataHealth(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error en DataHealth service", "error", err)
+		down := HealthCheckResponse{Status: HealthStatusDown}
+		respondWithHealth(w, r, map[string]HealthCheckResponse{"sync": down, "rows": down})
+		return
+	}
+
+	sync := HealthCheckResponse{Status: HealthStatusOK}
+	if health.LastSync != nil {
+		sync.LastSyncAt = &health.LastSync.FinishedAt
+	}
+	if health.StaleAfter > 0 {
+		sync.StaleAfter = health.StaleAfter.String()
+	}
+	if health.Stale {
+		sync.Status = HealthStatusDegraded
+	}
+
+	rows := HealthCheckResponse{Status: HealthStatusOK, RowCount: &health.RowCount}
+	if health.RowCount == 0 {
+		rows.Status = HealthStatusDegraded
+	}
+
+	respondWithHealth(w, r, map[string]HealthCheckResponse{"sync": sync, "rows": rows})
+}
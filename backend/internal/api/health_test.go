@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/core"
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// downStore is a sync store and pinger whose database does not answer.
+type downStore struct {
+	*store.MemoryStore
+}
+
+var errDatabaseDown = errors.New("database is down")
+
+func (downStore) Ping(ctx context.Context) error { return errDatabaseDown }
+func (downStore) LastSyncRun(ctx context.Context) (*core.SyncRun, error) {
+	return nil, errDatabaseDown
+}
+
+func serveHealth(t *testing.T, handler http.HandlerFunc) (int, HealthResponse) {
+	t.Helper()
+
+	r := chi.NewRouter()
+	r.Get("/health", handler)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	var response HealthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return rec.Code, response
+}
+
+func TestHealthHandler_LiveAndReady(t *testing.T) {
+	memoryStore := store.NewMemoryStore(nil)
+	healthy := NewHealthHandler(services.NewHealthService(memoryStore, memoryStore, memoryStore, 0))
+	down := NewHealthHandler(services.NewHealthService(memoryStore, downStore{memoryStore}, downStore{memoryStore}, 0))
+
+	code, response := serveHealth(t, down.Live)
+	assert.Equal(t, http.StatusOK, code, "liveness does not depend on the database")
+	assert.Equal(t, HealthStatusOK, response.Status)
+
+	code, response = serveHealth(t, healthy.Ready)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusOK, response.Checks["database"].Status)
+
+	code, response = serveHealth(t, down.Ready)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthStatusDown, response.Status)
+	assert.Equal(t, HealthStatusDown, response.Checks["database"].Status)
+}
+
+func TestHealthHandler_Data(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	t.Run("fresh", func(t *testing.T) {
+		memoryStore := store.NewMemoryStore([]core.Stock{{Ticker: "AAPL"}, {Ticker: "MSFT"}})
+		require.NoError(t, memoryStore.RecordSyncRun(ctx, &core.SyncRun{RunID: "run", FinishedAt: now.Add(-time.Hour)}))
+		handler := NewHealthHandler(services.NewHealthService(memoryStore, memoryStore, memoryStore, 24*time.Hour))
+
+		code, response := serveHealth(t, handler.Data)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, HealthStatusOK, response.Status)
+		require.NotNil(t, response.Checks["sync"].LastSyncAt)
+		assert.True(t, response.Checks["sync"].LastSyncAt.Equal(now.Add(-time.Hour)))
+		assert.Equal(t, "24h0m0s", response.Checks["sync"].StaleAfter)
+		require.NotNil(t, response.Checks["rows"].RowCount)
+		assert.Equal(t, int64(2), *response.Checks["rows"].RowCount)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		memoryStore := store.NewMemoryStore([]core.Stock{{Ticker: "AAPL"}})
+		require.NoError(t, memoryStore.RecordSyncRun(ctx, &core.SyncRun{RunID: "run", FinishedAt: now.Add(-48 * time.Hour)}))
+		handler := NewHealthHandler(services.NewHealthService(memoryStore, memoryStore, memoryStore, 24*time.Hour))
+
+		code, response := serveHealth(t, handler.Data)
+		assert.Equal(t, http.StatusOK, code, "degraded data still serves requests")
+		assert.Equal(t, HealthStatusDegraded, response.Status)
+		assert.Equal(t, HealthStatusDegraded, response.Checks["sync"].Status)
+		assert.Equal(t, HealthStatusOK, response.Checks["rows"].Status)
+	})
+
+	t.Run("never synced and empty", func(t *testing.T) {
+		memoryStore := store.NewMemoryStore(nil)
+		handler := NewHealthHandler(services.NewHealthService(memoryStore, memoryStore, memoryStore, 0))
+
+		_, response := serveHealth(t, handler.Data)
+		assert.Equal(t, HealthStatusDegraded, response.Status)
+		assert.Nil(t, response.Checks["sync"].LastSyncAt)
+		assert.Equal(t, HealthStatusDegraded, response.Checks["sync"].Status)
+		assert.Equal(t, HealthStatusDegraded, response.Checks["rows"].Status)
+	})
+
+	t.Run("database down", func(t *testing.T) {
+		memoryStore := store.NewMemoryStore(nil)
+		handler := NewHealthHandler(services.NewHealthService(memoryStore, downStore{memoryStore}, downStore{memoryStore}, 0))
+
+		code, response := serveHealth(t, handler.Data)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, HealthStatusDown, response.Status)
+	})
+}
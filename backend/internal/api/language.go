@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"stockify/internal/i18n"
+	"strings"
+)
+
+// negotiateLanguage picks the language of the messages of the response: the
+// lang query parameter when present, or else the best match for the
+// Accept-Language header. The language is stored in the request context and
+// announced in Content-Language.
+func negotiateLanguage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		r = r.WithContext(i18n.WithLang(r.Context(), lang))
+
+		if code := r.URL.Query().Get("lang"); code != "" {
+			requested, ok := i18n.Parse(code)
+			if !ok {
+				supported := make([]string, 0, len(i18n.Supported))
+				for _, lang := range i18n.Supported {
+					supported = append(supported, string(lang))
+				}
+				respondWithValidationError(w, r, i18n.MsgInvalidLang, newFieldError("lang", i18n.MsgFieldAllowedValues, i18n.Params{"values": strings.Join(supported, ", ")}))
+				return
+			}
+			lang = requested
+			r = r.WithContext(i18n.WithLang(r.Context(), lang))
+		}
+
+		w.Header().Set("Content-Language", string(lang))
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r)
+	})
+}
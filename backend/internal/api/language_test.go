@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Language(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		acceptLanguage string
+		wantLang       string
+		wantDetail     string
+	}{
+		{"default", "/api/v1/stocks/NOPE", "", "es", "Stock no encontrado"},
+		{"accept language", "/api/v1/stocks/NOPE", "en-GB,en;q=0.8", "en", "Stock not found"},
+		{"weighted accept language", "/api/v1/stocks/NOPE", "fr, en;q=0.5, es;q=0.9", "es", "Stock no encontrado"},
+		{"unsupported accept language", "/api/v1/stocks/NOPE", "de", "es", "Stock no encontrado"},
+		{"query overrides header", "/api/v1/stocks/NOPE?lang=en", "es", "en", "Stock not found"},
+	}
+
+	router := newTestRouter(t, newAdminTestConfig(), nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusNotFound, rec.Code)
+			assert.Equal(t, tt.wantLang, rec.Header().Get("Content-Language"))
+			assert.Contains(t, rec.Header().Values("Vary"), "Accept-Language")
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, tt.wantDetail, problem.Detail)
+		})
+	}
+}
+
+func TestGetRecommendations_ETagVariesByLanguage(t *testing.T) {
+	router := newTestRouter(t, newAdminTestConfig(), nil)
+
+	etag := func(lang string) string {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stocks/recommendations?lang="+lang, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Header().Get("ETag")
+	}
+
+	assert.NotEqual(t, etag("es"), etag("en"))
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"stockify/internal/logging"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// withRequestID makes every line logged while serving the request carry the
+// ID assigned by middleware.RequestID, which must run before it.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := logging.With(r.Context(), slog.String("request_id", middleware.GetReqID(r.Context())))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// logRequests logs one line per request once it is served, at error level
+// for server errors.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			slog.Log(r.Context(), level, "Petición HTTP",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"duration_ms", time.Since(start).Milliseconds(),
+				"remote_addr", r.RemoteAddr,
+			)
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// recoverPanics answers 500 to requests whose handler panics, logging the
+// panic and its stack trace. Like net/http, it lets http.ErrAbortHandler
+// through so that the connection is aborted.
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+			if rvr == http.ErrAbortHandler {
+				panic(rvr)
+			}
+
+			slog.ErrorContext(r.Context(), "Pánico atendiendo la petición", "panic", rvr, "stack", string(debug.Stack()))
+			if r.Header.Get("Connection") != "Upgrade" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/logging"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs makes the default logger write JSON lines to a buffer for the
+// rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(logging.NewHandler(&buf, slog.LevelDebug)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(raw), &line), "line %q is not JSON", raw)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func newLoggingTestRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID, withRequestID, logRequests, recoverPanics)
+	r.Get("/ok", func(w http.ResponseWriter, r *http.Request) {
+		slog.InfoContext(r.Context(), "desde el handler")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	r.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	return r
+}
+
+func TestLogging_RequestIDOnEveryLine(t *testing.T) {
+	buf := captureLogs(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	newLoggingTestRouter().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, "desde el handler", lines[0]["msg"])
+	assert.Equal(t, "req-123", lines[0]["request_id"])
+
+	assert.Equal(t, "Petición HTTP", lines[1]["msg"])
+	assert.Equal(t, "INFO", lines[1]["level"])
+	assert.Equal(t, "req-123", lines[1]["request_id"])
+	assert.Equal(t, "/ok", lines[1]["path"])
+	assert.Equal(t, float64(http.StatusAccepted), lines[1]["status"])
+}
+
+func TestLogging_RecoversPanics(t *testing.T) {
+	buf := captureLogs(t)
+
+	rec := httptest.NewRecorder()
+	newLoggingTestRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, "boom", lines[0]["panic"])
+	assert.NotEmpty(t, lines[0]["stack"])
+	assert.NotEmpty(t, lines[0]["request_id"], "the generated request ID is logged when the client sends none")
+
+	assert.Equal(t, "ERROR", lines[1]["level"], "server errors are logged at error level")
+	assert.Equal(t, lines[0]["request_id"], lines[1]["request_id"])
+}
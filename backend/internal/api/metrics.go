@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"stockify/internal/metrics"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// unmatchedRoute labels the requests that matched no route, so that unknown
+// paths do not each create a new series.
+const unmatchedRoute = "unmatched"
+
+// instrument records the count and latency of every request under the route
+// pattern it matched. The pattern is only complete once the request has gone
+// through every subrouter, so it is read after next returns.
+func instrument(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+
+			defer func() {
+				route := unmatchedRoute
+				if rctx := chi.RouteContext(r.Context()); rctx != nil {
+					if pattern := rctx.RoutePattern(); pattern != "" {
+						route = pattern
+					}
+				}
+
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				m.ObserveHTTPRequest(r.Method, route, status, time.Since(start))
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Metrics(t *testing.T) {
+	router := newTestRouter(t, newAdminTestConfig(), nil)
+
+	for _, path := range []string{"/api/v1/stocks/AAPL", "/api/v1/stocks/MSFT", "/api/stocks/AAPL", "/does-not-exist"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `stockify_http_requests_total{method="GET",route="/api/v1/stocks/{ticker}",status="404"} 2`, "requests are labelled by route pattern, not path")
+	assert.Contains(t, body, `stockify_http_requests_total{method="GET",route="/api/stocks/{ticker}",status="404"} 1`)
+	assert.Contains(t, body, `stockify_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, "AAPL")
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type openAPIDocument struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	var spec openAPIDocument
+	require.NoError(t, json.Unmarshal(openAPISpec, &spec))
+	assert.True(t, strings.HasPrefix(spec.OpenAPI, "3."))
+
+	registered := make(map[string]bool)
+	routes, ok := newTestRouter(t, newAdminTestConfig(), nil).(chi.Routes)
+	require.True(t, ok, "NewRouter must return a chi router")
+
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		// Routes under the unversioned /api prefix are aliases of /api/v1.
+		if rest, ok := strings.CutPrefix(route, "/api/"); ok && !strings.HasPrefix(rest, "v1/") {
+			route = "/api/v1/" + rest
+		}
+		operation := strings.ToLower(method) + " " + route
+		registered[operation] = true
+
+		_, documented := spec.Paths[route][strings.ToLower(method)]
+		assert.True(t, documented, "route %s %s is missing from openapi.json", method, route)
+		return nil
+	})
+	require.NoError(t, err)
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			assert.True(t, registered[method+" "+path], "openapi.json documents %s %s, which is not registered", strings.ToUpper(method), path)
+		}
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestRouter(t, newAdminTestConfig(), nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, string(openAPISpec), rec.Body.String())
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"stockify/internal/i18n"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Stable error codes returned in the code member of every problem. Clients
+// should branch on these rather than on the localized detail message.
+const (
+	ErrCodeRouteNotFound        = "route_not_found"
+	ErrCodeMethodNotAllowed     = "method_not_allowed"
+	ErrCodeInvalidParameter     = "invalid_parameter"
+	ErrCodeUnauthorized         = "unauthorized"
+	ErrCodeStockNotFound        = "stock_not_found"
+	ErrCodeDeletedStockNotFound = "deleted_stock_not_found"
+	ErrCodeInvalidImportFile    = "invalid_import_file"
+	ErrCodePayloadTooLarge      = "payload_too_large"
+	ErrCodeInternal             = "internal_error"
+)
+
+// problemTypePrefix namespaces the problem type URIs. Every code maps to the
+// type problemTypePrefix + code.
+const problemTypePrefix = "urn:stockify:problem:"
+
+// Problem is an RFC 7807 problem details object, extended with a stable
+// error code, the request ID and per-field validation errors.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why a single request parameter was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+
+	key    i18n.Key
+	params i18n.Params
+}
+
+// newFieldError rejects field for the reason key, which is worded in the
+// language of the request when the problem is sent.
+func newFieldError(field string, key i18n.Key, params i18n.Params) FieldError {
+	return FieldError{Field: field, key: key, params: params}
+}
+
+func respondWithProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	problem.Type = problemTypePrefix + problem.Code
+	problem.Title = http.StatusText(problem.Status)
+	problem.Instance = r.URL.Path
+	problem.RequestID = middleware.GetReqID(r.Context())
+
+	lang := i18n.FromContext(r.Context())
+	for i, fieldErr := range problem.Errors {
+		if fieldErr.key != "" {
+			problem.Errors[i].Message = i18n.T(lang, fieldErr.key, fieldErr.params)
+		}
+	}
+
+	response, err := json.Marshal(problem)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error marshalling problem", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Errors must not be cached nor carry the validators of the
+	// representation the request was after.
+	w.Header().Del("ETag")
+	w.Header().Del("Last-Modified")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	w.Write(response)
+}
+
+// respondWithError sends a problem whose detail is the message key worded in
+// the language of the request.
+func respondWithError(w http.ResponseWriter, r *http.Request, status int, code string, message i18n.Key) {
+	detail := i18n.T(i18n.FromContext(r.Context()), message, nil)
+	respondWithProblem(w, r, Problem{Status: status, Code: code, Detail: detail})
+}
+
+// respondWithValidationError rejects the request with 400 and the parameters
+// that failed validation.
+func respondWithValidationError(w http.ResponseWriter, r *http.Request, message i18n.Key, fieldErrors ...FieldError) {
+	detail := i18n.T(i18n.FromContext(r.Context()), message, nil)
+	respondWithProblem(w, r, Problem{Status: http.StatusBadRequest, Code: ErrCodeInvalidParameter, Detail: detail, Errors: fieldErrors})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Problems(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		wantStatus int
+		wantCode   string
+		wantFields []string
+	}{
+		{"stock not found", http.MethodGet, "/api/v1/stocks/NOPE", http.StatusNotFound, ErrCodeStockNotFound, nil},
+		{"unversioned alias", http.MethodGet, "/api/stocks/NOPE", http.StatusNotFound, ErrCodeStockNotFound, nil},
+		{"invalid view", http.MethodGet, "/api/v1/stocks?view=weekly", http.StatusBadRequest, ErrCodeInvalidParameter, []string{"view"}},
+		{"invalid audit filters", http.MethodGet, "/api/v1/admin/audit?stock_id=x&from=yesterday", http.StatusBadRequest, ErrCodeInvalidParameter, []string{"stock_id", "from"}},
+		{"invalid sortBy", http.MethodGet, "/api/v1/stocks?sortBy=password", http.StatusBadRequest, ErrCodeInvalidParameter, []string{"sortBy"}},
+		{"invalid sortOrder", http.MethodGet, "/api/v1/stocks?view=latest&sortOrder=sideways", http.StatusBadRequest, ErrCodeInvalidParameter, []string{"sortOrder"}},
+		{"invalid export sortBy", http.MethodGet, "/api/v1/stocks/export?sortBy=password", http.StatusBadRequest, ErrCodeInvalidParameter, []string{"sortBy"}},
+		{"invalid lang", http.MethodGet, "/api/v1/stocks?lang=fr", http.StatusBadRequest, ErrCodeInvalidParameter, []string{"lang"}},
+		{"unknown route", http.MethodGet, "/api/v1/unknown", http.StatusNotFound, ErrCodeRouteNotFound, nil},
+		{"wrong method", http.MethodPost, "/api/v1/stocks", http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, nil},
+	}
+
+	router := newTestRouter(t, newAdminTestConfig(), nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			req.Header.Set("X-API-Key", "key")
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, tt.wantCode, problem.Code)
+			assert.Equal(t, "urn:stockify:problem:"+tt.wantCode, problem.Type)
+			assert.Equal(t, tt.wantStatus, problem.Status)
+			assert.Equal(t, http.StatusText(tt.wantStatus), problem.Title)
+			assert.NotEmpty(t, problem.Detail)
+			assert.NotEmpty(t, problem.RequestID)
+
+			var fields []string
+			for _, fieldError := range problem.Errors {
+				fields = append(fields, fieldError.Field)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestGetStocks_InvalidSortListsAllowedColumns(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestRouter(t, newAdminTestConfig(), nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stocks?sortBy=password&lang=en", nil))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "sortBy", problem.Errors[0].Field)
+	assert.Contains(t, problem.Errors[0].Message, "ticker, company")
+	assert.NotContains(t, problem.Errors[0].Message, "event_count")
+}
@@ -1,48 +1,123 @@
 package api
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
+	"stockify/internal/api/graph"
+	"stockify/internal/config"
+	"stockify/internal/i18n"
+	"stockify/internal/metrics"
 	"stockify/internal/services"
+	"stockify/internal/store"
+	"stockify/internal/stream"
+	"stockify/internal/tasks"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
 )
 
-func NewRouter(stockService *services.StockService, recommendationService *services.RecommendationService) http.Handler {
-	r := chi.NewRouter()
+// CacheStatsProvider exposes the hit and miss counters of the store cache.
+type CacheStatsProvider interface {
+	Stats() store.CacheStats
+}
 
-	corsMiddleware := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173", "http://127.0.0.1:5173", "http://localhost:3000", "http://127.0.0.1:3000"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With", "Cache-Control", "Pragma", "Expires"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	})
+// NewRouter builds the HTTP API under /api/v1, with /api kept as an alias.
+// cacheStats may be nil when the store is not cached, in which case the cache
+// statistics route is not registered. Likewise, requests are only measured
+// and /metrics only served when m is not nil.
+func NewRouter(cfg *config.Config, stockService *services.StockService, recommendationService *services.RecommendationService, auditService *services.AuditService, healthService *services.HealthService, importService *tasks.ImportService, broker *stream.Broker, cacheStats CacheStatsProvider, m *metrics.Metrics) http.Handler {
+	r := chi.NewRouter()
 
-	r.Use(corsMiddleware.Handler)
+	if corsMiddleware := newCORS(cfg.CORS); corsMiddleware != nil {
+		r.Use(corsMiddleware)
+	}
+	r.Use(securityHeaders(cfg.SecurityHeaders))
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	r.Use(withRequestID)
+	if m != nil {
+		r.Use(instrument(m))
+	}
+	r.Use(logRequests)
+	r.Use(recoverPanics)
+
+	stockHandler := NewStockHandler(stockService, recommendationService, cfg.HTTPCacheMaxAge)
+	adminHandler := NewAdminHandler(stockService, auditService, importService)
+	streamHandler := NewStreamHandler(stockService, broker)
+	healthHandler := NewHealthHandler(healthService)
 
-	stockHandler := NewStockHandler(stockService, recommendationService)
+	apiRouter := chi.NewRouter()
+	apiRouter.Use(negotiateLanguage)
 
-	r.Route("/api", func(apiRouter chi.Router) {
+	apiRouter.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		respondWithError(w, r, http.StatusNotFound, ErrCodeRouteNotFound, i18n.MsgRouteNotFound)
+	})
+	apiRouter.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		respondWithError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, i18n.MsgMethodNotAllowed)
+	})
+
+	apiRouter.Group(func(apiRouter chi.Router) {
 		apiRouter.Route("/stocks", func(stocksRouter chi.Router) {
 			stocksRouter.Get("/", stockHandler.GetStocks)
+			stocksRouter.Get("/export", stockHandler.ExportStocks)
 			stocksRouter.Get("/recommendations", stockHandler.GetRecommendations)
 			stocksRouter.Get("/{ticker}", stockHandler.GetStockByTicker)
 		})
+
+		apiRouter.Get("/stream/events", streamHandler.StreamEvents)
+
+		apiRouter.Post("/graphql", graph.NewHandler(stockService, recommendationService).ServeHTTP)
+
+		apiRouter.Route("/admin", func(adminRouter chi.Router) {
+			adminRouter.Use(requireAdmin(cfg.AdminAPIKeys))
+
+			adminRouter.Get("/audit", adminHandler.GetAuditEntries)
+			adminRouter.Post("/import", adminHandler.ImportStocks)
+
+			adminRouter.Route("/stocks", func(adminStocksRouter chi.Router) {
+				adminStocksRouter.Delete("/{id}", adminHandler.DeleteStock)
+				adminStocksRouter.Get("/deleted", adminHandler.GetDeletedStocks)
+				adminStocksRouter.Delete("/deleted/{id}", adminHandler.PurgeStock)
+				adminStocksRouter.Post("/deleted/{id}/restore", adminHandler.RestoreStock)
+			})
+		})
+
+		apiRouter.Get("/openapi.json", serveOpenAPI)
+
+		if cacheStats != nil {
+			apiRouter.Get("/cache/stats", func(w http.ResponseWriter, r *http.Request) {
+				respondWithJSON(w, r, http.StatusOK, cacheStats.Stats())
+			})
+		}
 	})
 
+	// /api/v1 is the canonical prefix; /api serves the same routes so that
+	// clients written before versioning keep working.
+	r.Mount("/api/v1", apiRouter)
+	r.Mount("/api", apiRouter)
+
+	// /health predates the detailed checks and keeps answering a plain OK
+	// for the probes that still use it.
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("OK"))
 	})
+	r.Get("/health/live", healthHandler.Live)
+	r.Get("/health/ready", healthHandler.Ready)
+	r.Get("/health/data", healthHandler.Data)
+
+	if m != nil {
+		r.Method(http.MethodGet, "/metrics", m.Handler())
+	}
+
+	if len(cfg.CORS.AllowedOrigins) == 0 {
+		slog.Warn("CORS_ALLOWED_ORIGINS está vacío, los navegadores solo permitirán peticiones del mismo origen")
+	}
+
+	if len(cfg.AdminAPIKeys) == 0 {
+		slog.Warn("ADMIN_API_KEYS no está configurado, las rutas /api/admin rechazarán todas las peticiones")
+	}
 
-	log.Println("Router configurado con CORS y rutas API.")
+	slog.Debug("Router configurado con CORS y rutas API")
 	return r
 }
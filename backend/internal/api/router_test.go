@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"stockify/internal/config"
+	"stockify/internal/core"
+	"stockify/internal/metrics"
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"stockify/internal/stream"
+	"stockify/internal/tasks"
+	"testing"
+)
+
+// newAdminTestConfig enables the admin routes, which accept the API key "key".
+func newAdminTestConfig() *config.Config {
+	return &config.Config{AdminAPIKeys: map[string]string{"key": "test"}}
+}
+
+// newTestRouter builds the router configured by cfg, with every optional
+// route registered, over a memory store holding stocks.
+func newTestRouter(t *testing.T, cfg *config.Config, stocks []core.Stock) http.Handler {
+	t.Helper()
+
+	memoryStore := store.NewMemoryStore(stocks)
+	return NewRouter(
+		cfg,
+		services.NewStockService(memoryStore),
+		services.NewRecommendationService(memoryStore),
+		services.NewAuditService(memoryStore),
+		services.NewHealthService(memoryStore, memoryStore, memoryStore, 0),
+		tasks.NewImportService(memoryStore),
+		stream.NewBroker(),
+		store.NewCachingStore(memoryStore, 1, 0),
+		metrics.New(),
+	)
+}
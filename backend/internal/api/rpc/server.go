@@ -0,0 +1,232 @@
+// Package rpc serves the stock queries of the REST API over gRPC, for
+// internal services that prefer typed calls to parsing JSON. The service is
+// defined in stockpb/stock.proto; the generated code is checked in and is
+// rebuilt with go generate.
+package rpc
+
+//go:generate protoc --proto_path=stockpb --go_out=stockpb --go_opt=paths=source_relative --go-grpc_out=stockpb --go-grpc_opt=paths=source_relative stockpb/stock.proto
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"runtime/debug"
+	"stockify/internal/api/rpc/stockpb"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/logging"
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements stockpb.StockServiceServer on top of the same services
+// as the HTTP handlers.
+type Server struct {
+	stockpb.UnimplementedStockServiceServer
+
+	stockService          *services.StockService
+	recommendationService *services.RecommendationService
+}
+
+func NewServer(stockService *services.StockService, recommendationService *services.RecommendationService) *Server {
+	return &Server{stockService: stockService, recommendationService: recommendationService}
+}
+
+// NewGRPCServer returns a gRPC server with the stock service registered,
+// every call logged, panics answered with codes.Internal and messages worded
+// in the language of the call.
+func NewGRPCServer(stockService *services.StockService, recommendationService *services.RecommendationService) *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(logCalls, negotiateLanguage, recoverPanics))
+	stockpb.RegisterStockServiceServer(grpcServer, NewServer(stockService, recommendationService))
+	return grpcServer
+}
+
+func (s *Server) ListStocks(ctx context.Context, req *stockpb.ListStocksRequest) (*stockpb.ListStocksResponse, error) {
+	params := store.GetStocksParams{
+		Search:          req.GetSearch(),
+		SortBy:          req.GetSortBy(),
+		SortOrder:       req.GetSortOrder(),
+		Page:            int(req.GetPage()),
+		PageSize:        int(req.GetPageSize()),
+		IncludeArchived: req.GetIncludeArchived(),
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 10
+	}
+
+	lang := i18n.FromContext(ctx)
+	stocks, totalItems, err := s.stockService.ListStocks(ctx, params)
+	var sortErr *store.InvalidSortError
+	if errors.As(err, &sortErr) {
+		message := i18n.MsgInvalidSortBy
+		if sortErr.Field == "SortOrder" {
+			message = i18n.MsgInvalidSortOrder
+		}
+		return nil, invalidArgument(lang, message, i18n.MsgFieldAllowedValues, i18n.Params{"values": strings.Join(sortErr.Allowed, ", ")})
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error en ListStocks service (gRPC)", "error", err)
+		return nil, statusError(lang, codes.Internal, i18n.MsgListStocksFailed)
+	}
+
+	response := &stockpb.ListStocksResponse{
+		Stocks:     make([]*stockpb.Stock, 0, len(stocks)),
+		TotalItems: totalItems,
+		Page:       int32(params.Page),
+		PageSize:   int32(params.PageSize),
+		TotalPages: int32(math.Ceil(float64(totalItems) / float64(params.PageSize))),
+	}
+	for _, stock := range stocks {
+		response.Stocks = append(response.Stocks, toProtoStock(stock))
+	}
+
+	return response, nil
+}
+
+func (s *Server) GetStock(ctx context.Context, req *stockpb.GetStockRequest) (*stockpb.Stock, error) {
+	lang := i18n.FromContext(ctx)
+	if req.GetTicker() == "" {
+		return nil, statusError(lang, codes.InvalidArgument, i18n.MsgTickerRequired)
+	}
+
+	stock, err := s.stockService.GetStockByTicker(ctx, req.GetTicker(), req.GetIncludeArchived())
+	if err != nil {
+		slog.ErrorContext(ctx, "Error en GetStockByTicker service (gRPC)", "ticker", req.GetTicker(), "error", err)
+		return nil, statusError(lang, codes.Internal, i18n.MsgGetStockFailed)
+	}
+	if stock == nil {
+		return nil, statusError(lang, codes.NotFound, i18n.MsgStockNotFound)
+	}
+
+	return toProtoStock(*stock), nil
+}
+
+func (s *Server) GetRecommendations(ctx context.Context, req *stockpb.GetRecommendationsRequest) (*stockpb.GetRecommendationsResponse, error) {
+	lang := i18n.FromContext(ctx)
+	if req.GetLimit() < 0 {
+		return nil, invalidArgument(lang, i18n.MsgInvalidLimit, i18n.MsgFieldNonNegative, nil)
+	}
+
+	recommendations, err := s.recommendationService.GetRecommendations(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error en GetRecommendations service (gRPC)", "error", err)
+		return nil, statusError(lang, codes.Internal, i18n.MsgRecommendationsFailed)
+	}
+
+	if limit := int(req.GetLimit()); limit > 0 && limit < len(recommendations) {
+		recommendations = recommendations[:limit]
+	}
+
+	response := &stockpb.GetRecommendationsResponse{
+		Recommendations: make([]*stockpb.Recommendation, 0, len(recommendations)),
+	}
+	for _, recommendation := range recommendations {
+		reasons := make([]*stockpb.RecommendationReason, 0, len(recommendation.Reasons))
+		for _, reason := range recommendation.Reasons {
+			reasons = append(reasons, &stockpb.RecommendationReason{
+				Type:    string(reason.Type),
+				Details: reason.Localize(lang),
+				Params:  reason.StringParams(),
+			})
+		}
+
+		response.Recommendations = append(response.Recommendations, &stockpb.Recommendation{
+			Stock:   toProtoStock(recommendation.Stock),
+			Score:   recommendation.Score,
+			Reasons: reasons,
+		})
+	}
+
+	return response, nil
+}
+
+// statusError returns a status with code and the message key worded in lang.
+func statusError(lang i18n.Lang, code codes.Code, message i18n.Key) error {
+	return status.Error(code, i18n.T(lang, message, nil))
+}
+
+// invalidArgument rejects an argument with the message key followed by the
+// reason, both worded in lang.
+func invalidArgument(lang i18n.Lang, message, reason i18n.Key, params i18n.Params) error {
+	return status.Errorf(codes.InvalidArgument, "%s: %s", i18n.T(lang, message, nil), i18n.T(lang, reason, params))
+}
+
+func toProtoStock(stock core.Stock) *stockpb.Stock {
+	return &stockpb.Stock{
+		Id:         uint64(stock.ID),
+		Ticker:     stock.Ticker,
+		Company:    stock.Company,
+		Brokerage:  stock.Brokerage,
+		Action:     stock.Action,
+		RatingFrom: stock.RatingFrom,
+		RatingTo:   stock.RatingTo,
+		TargetFrom: stock.TargetFrom,
+		TargetTo:   stock.TargetTo,
+		Time:       timestamppb.New(stock.Time),
+	}
+}
+
+// logCalls logs every unary call with its status code and duration, as the
+// HTTP router does for requests. Every line logged while serving the call
+// carries its method.
+func logCalls(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = logging.With(ctx, slog.String("grpc_method", info.FullMethod))
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	level := slog.LevelInfo
+	if code == codes.Internal || code == codes.Unknown {
+		level = slog.LevelError
+	}
+	slog.Log(ctx, level, "Llamada gRPC", "code", code.String(), "duration_ms", time.Since(start).Milliseconds())
+	return resp, err
+}
+
+// recoverPanics answers codes.Internal to calls whose handler panics, logging
+// the panic and its stack trace, so that a bug in one call does not take the
+// whole server down.
+func recoverPanics(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if rvr := recover(); rvr != nil {
+			slog.ErrorContext(ctx, "Pánico atendiendo la llamada gRPC", "panic", rvr, "stack", string(debug.Stack()))
+			resp, err = nil, statusError(i18n.FromContext(ctx), codes.Internal, i18n.MsgInternalError)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// negotiateLanguage picks the language of the messages of the call, as the
+// HTTP API does: the lang metadata when present, or else the best match for
+// the accept-language metadata.
+func negotiateLanguage(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	lang := i18n.Negotiate(strings.Join(md.Get("accept-language"), ","))
+
+	if values := md.Get("lang"); len(values) > 0 {
+		requested, ok := i18n.Parse(values[0])
+		if !ok {
+			supported := make([]string, 0, len(i18n.Supported))
+			for _, lang := range i18n.Supported {
+				supported = append(supported, string(lang))
+			}
+			return nil, invalidArgument(lang, i18n.MsgInvalidLang, i18n.MsgFieldAllowedValues, i18n.Params{"values": strings.Join(supported, ", ")})
+		}
+		lang = requested
+	}
+
+	return handler(i18n.WithLang(ctx, lang), req)
+}
@@ -0,0 +1,151 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"stockify/internal/api/rpc/stockpb"
+	"stockify/internal/core"
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves the stock service over an in-memory listener and
+// returns a client connected to it.
+func newTestClient(t *testing.T) stockpb.StockServiceClient {
+	t.Helper()
+
+	targetTo := 215.5
+	ratingFrom := "Hold"
+	memoryStore := store.NewMemoryStore([]core.Stock{
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker A", Action: "upgraded by", RatingFrom: &ratingFrom, RatingTo: "Buy", TargetTo: &targetTo, Time: time.Now().Add(-24 * time.Hour)},
+		{Ticker: "MSFT", Company: "Microsoft Corp.", Brokerage: "Broker B", Action: "initiated by", RatingTo: "Outperform", Time: time.Now().Add(-48 * time.Hour)},
+		{Ticker: "NVDA", Company: "NVIDIA Corp.", Brokerage: "Broker C", Action: "reiterated by", RatingTo: "Neutral", Time: time.Now().Add(-72 * time.Hour)},
+	})
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(services.NewStockService(memoryStore), services.NewRecommendationService(memoryStore))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return stockpb.NewStockServiceClient(conn)
+}
+
+func TestListStocks(t *testing.T) {
+	client := newTestClient(t)
+
+	response, err := client.ListStocks(context.Background(), &stockpb.ListStocksRequest{SortBy: "ticker", SortOrder: "asc", PageSize: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), response.GetTotalItems())
+	assert.Equal(t, int32(1), response.GetPage())
+	assert.Equal(t, int32(2), response.GetPageSize())
+	assert.Equal(t, int32(2), response.GetTotalPages())
+	require.Len(t, response.GetStocks(), 2)
+
+	apple := response.GetStocks()[0]
+	assert.Equal(t, "AAPL", apple.GetTicker())
+	assert.Equal(t, "Hold", apple.GetRatingFrom())
+	assert.Equal(t, 215.5, apple.GetTargetTo())
+	assert.Nil(t, apple.TargetFrom)
+	assert.NotZero(t, apple.GetId())
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), apple.GetTime().AsTime(), time.Minute)
+}
+
+func TestListStocks_InvalidSort(t *testing.T) {
+	client := newTestClient(t)
+
+	_, err := client.ListStocks(context.Background(), &stockpb.ListStocksRequest{SortBy: "password"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "ticker, company")
+
+	_, err = client.ListStocks(context.Background(), &stockpb.ListStocksRequest{SortOrder: "sideways"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetStock(t *testing.T) {
+	client := newTestClient(t)
+
+	stock, err := client.GetStock(context.Background(), &stockpb.GetStockRequest{Ticker: "MSFT"})
+	require.NoError(t, err)
+	assert.Equal(t, "Microsoft Corp.", stock.GetCompany())
+
+	_, err = client.GetStock(context.Background(), &stockpb.GetStockRequest{Ticker: "ZZZZ"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetStock(context.Background(), &stockpb.GetStockRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetRecommendations(t *testing.T) {
+	client := newTestClient(t)
+
+	response, err := client.GetRecommendations(context.Background(), &stockpb.GetRecommendationsRequest{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, response.GetRecommendations(), 1)
+
+	recommendation := response.GetRecommendations()[0]
+	assert.Equal(t, "AAPL", recommendation.GetStock().GetTicker())
+	assert.Positive(t, recommendation.GetScore())
+	assert.NotEmpty(t, recommendation.GetReasons())
+
+	for _, reason := range recommendation.GetReasons() {
+		assert.NotEmpty(t, reason.GetParams(), "reason %s has no params", reason.GetType())
+	}
+
+	_, err = client.GetRecommendations(context.Background(), &stockpb.GetRecommendationsRequest{Limit: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestLanguageMetadata(t *testing.T) {
+	client := newTestClient(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "accept-language", "en-US")
+	response, err := client.GetRecommendations(ctx, &stockpb.GetRecommendationsRequest{Limit: 1})
+	require.NoError(t, err)
+	reasons := response.GetRecommendations()[0].GetReasons()
+	require.NotEmpty(t, reasons)
+	assert.Equal(t, "Recent rating event (last 3 months).", reasons[len(reasons)-1].GetDetails())
+
+	_, err = client.GetStock(metadata.AppendToOutgoingContext(context.Background(), "lang", "en"), &stockpb.GetStockRequest{Ticker: "ZZZZ"})
+	assert.Equal(t, "Stock not found", status.Convert(err).Message())
+
+	_, err = client.GetStock(context.Background(), &stockpb.GetStockRequest{Ticker: "ZZZZ"})
+	assert.Equal(t, "Stock no encontrado", status.Convert(err).Message())
+
+	_, err = client.GetStock(metadata.AppendToOutgoingContext(context.Background(), "lang", "fr"), &stockpb.GetStockRequest{Ticker: "AAPL"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestRecoverPanics(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/stockify.v1.StockService/ListStocks"}
+
+	resp, err := recoverPanics(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	resp, err = recoverPanics(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
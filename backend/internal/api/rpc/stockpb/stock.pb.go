@@ -0,0 +1,704 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: stock.proto
+
+package stockpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Stock is a rating event of a ticker by a brokerage.
+type Stock struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ticker        string                 `protobuf:"bytes,2,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	Company       string                 `protobuf:"bytes,3,opt,name=company,proto3" json:"company,omitempty"`
+	Brokerage     string                 `protobuf:"bytes,4,opt,name=brokerage,proto3" json:"brokerage,omitempty"`
+	Action        string                 `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"`
+	RatingFrom    *string                `protobuf:"bytes,6,opt,name=rating_from,json=ratingFrom,proto3,oneof" json:"rating_from,omitempty"`
+	RatingTo      string                 `protobuf:"bytes,7,opt,name=rating_to,json=ratingTo,proto3" json:"rating_to,omitempty"`
+	TargetFrom    *float64               `protobuf:"fixed64,8,opt,name=target_from,json=targetFrom,proto3,oneof" json:"target_from,omitempty"`
+	TargetTo      *float64               `protobuf:"fixed64,9,opt,name=target_to,json=targetTo,proto3,oneof" json:"target_to,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stock) Reset() {
+	*x = Stock{}
+	mi := &file_stock_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stock) ProtoMessage() {}
+
+func (x *Stock) ProtoReflect() protoreflect.Message {
+	mi := &file_stock_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stock.ProtoReflect.Descriptor instead.
+func (*Stock) Descriptor() ([]byte, []int) {
+	return file_stock_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Stock) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Stock) GetTicker() string {
+	if x != nil {
+		return x.Ticker
+	}
+	return ""
+}
+
+func (x *Stock) GetCompany() string {
+	if x != nil {
+		return x.Company
+	}
+	return ""
+}
+
+func (x *Stock) GetBrokerage() string {
+	if x != nil {
+		return x.Brokerage
+	}
+	return ""
+}
+
+func (x *Stock) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Stock) GetRatingFrom() string {
+	if x != nil && x.RatingFrom != nil {
+		return *x.RatingFrom
+	}
+	return ""
+}
+
+func (x *Stock) GetRatingTo() string {
+	if x != nil {
+		return x.RatingTo
+	}
+	return ""
+}
+
+func (x *Stock) GetTargetFrom() float64 {
+	if x != nil && x.TargetFrom != nil {
+		return *x.TargetFrom
+	}
+	return 0
+}
+
+func (x *Stock) GetTargetTo() float64 {
+	if x != nil && x.TargetTo != nil {
+		return *x.TargetTo
+	}
+	return 0
+}
+
+func (x *Stock) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type ListStocksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// search matches the ticker or the company.
+	Search string `protobuf:"bytes,1,opt,name=search,proto3" json:"search,omitempty"`
+	// sort_by and sort_order behave as the sortBy and sortOrder query
+	// parameters of GET /api/v1/stocks: newest first when sort_by is empty.
+	SortBy    string `protobuf:"bytes,2,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	SortOrder string `protobuf:"bytes,3,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
+	// page starts at 1, which is also the default.
+	Page int32 `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`
+	// page_size defaults to 10.
+	PageSize        int32 `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	IncludeArchived bool  `protobuf:"varint,6,opt,name=include_archived,json=includeArchived,proto3" json:"include_archived,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListStocksRequest) Reset() {
+	*x = ListStocksRequest{}
+	mi := &file_stock_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStocksRequest) ProtoMessage() {}
+
+func (x *ListStocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stock_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStocksRequest.ProtoReflect.Descriptor instead.
+func (*ListStocksRequest) Descriptor() ([]byte, []int) {
+	return file_stock_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListStocksRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *ListStocksRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListStocksRequest) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
+func (x *ListStocksRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListStocksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListStocksRequest) GetIncludeArchived() bool {
+	if x != nil {
+		return x.IncludeArchived
+	}
+	return false
+}
+
+type ListStocksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stocks        []*Stock               `protobuf:"bytes,1,rep,name=stocks,proto3" json:"stocks,omitempty"`
+	TotalItems    int64                  `protobuf:"varint,2,opt,name=total_items,json=totalItems,proto3" json:"total_items,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	TotalPages    int32                  `protobuf:"varint,5,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStocksResponse) Reset() {
+	*x = ListStocksResponse{}
+	mi := &file_stock_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStocksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStocksResponse) ProtoMessage() {}
+
+func (x *ListStocksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stock_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStocksResponse.ProtoReflect.Descriptor instead.
+func (*ListStocksResponse) Descriptor() ([]byte, []int) {
+	return file_stock_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListStocksResponse) GetStocks() []*Stock {
+	if x != nil {
+		return x.Stocks
+	}
+	return nil
+}
+
+func (x *ListStocksResponse) GetTotalItems() int64 {
+	if x != nil {
+		return x.TotalItems
+	}
+	return 0
+}
+
+func (x *ListStocksResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListStocksResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListStocksResponse) GetTotalPages() int32 {
+	if x != nil {
+		return x.TotalPages
+	}
+	return 0
+}
+
+type GetStockRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Ticker          string                 `protobuf:"bytes,1,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	IncludeArchived bool                   `protobuf:"varint,2,opt,name=include_archived,json=includeArchived,proto3" json:"include_archived,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetStockRequest) Reset() {
+	*x = GetStockRequest{}
+	mi := &file_stock_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStockRequest) ProtoMessage() {}
+
+func (x *GetStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stock_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStockRequest.ProtoReflect.Descriptor instead.
+func (*GetStockRequest) Descriptor() ([]byte, []int) {
+	return file_stock_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetStockRequest) GetTicker() string {
+	if x != nil {
+		return x.Ticker
+	}
+	return ""
+}
+
+func (x *GetStockRequest) GetIncludeArchived() bool {
+	if x != nil {
+		return x.IncludeArchived
+	}
+	return false
+}
+
+type GetRecommendationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// limit caps the number of recommendations; zero returns them all.
+	Limit         int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRecommendationsRequest) Reset() {
+	*x = GetRecommendationsRequest{}
+	mi := &file_stock_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRecommendationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecommendationsRequest) ProtoMessage() {}
+
+func (x *GetRecommendationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stock_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecommendationsRequest.ProtoReflect.Descriptor instead.
+func (*GetRecommendationsRequest) Descriptor() ([]byte, []int) {
+	return file_stock_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetRecommendationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetRecommendationsResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Recommendations []*Recommendation      `protobuf:"bytes,1,rep,name=recommendations,proto3" json:"recommendations,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetRecommendationsResponse) Reset() {
+	*x = GetRecommendationsResponse{}
+	mi := &file_stock_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRecommendationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecommendationsResponse) ProtoMessage() {}
+
+func (x *GetRecommendationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stock_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecommendationsResponse.ProtoReflect.Descriptor instead.
+func (*GetRecommendationsResponse) Descriptor() ([]byte, []int) {
+	return file_stock_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetRecommendationsResponse) GetRecommendations() []*Recommendation {
+	if x != nil {
+		return x.Recommendations
+	}
+	return nil
+}
+
+type Recommendation struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Stock         *Stock                  `protobuf:"bytes,1,opt,name=stock,proto3" json:"stock,omitempty"`
+	Score         float64                 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Reasons       []*RecommendationReason `protobuf:"bytes,3,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Recommendation) Reset() {
+	*x = Recommendation{}
+	mi := &file_stock_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recommendation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recommendation) ProtoMessage() {}
+
+func (x *Recommendation) ProtoReflect() protoreflect.Message {
+	mi := &file_stock_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recommendation.ProtoReflect.Descriptor instead.
+func (*Recommendation) Descriptor() ([]byte, []int) {
+	return file_stock_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Recommendation) GetStock() *Stock {
+	if x != nil {
+		return x.Stock
+	}
+	return nil
+}
+
+func (x *Recommendation) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Recommendation) GetReasons() []*RecommendationReason {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+type RecommendationReason struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// type is one of the RecommendationReasonType values, e.g. BROKER_UPGRADE.
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// details is worded in the language of the call, taken from the lang or
+	// accept-language metadata.
+	Details string `protobuf:"bytes,2,opt,name=details,proto3" json:"details,omitempty"`
+	// params holds the values details is built from, e.g. brokerage or
+	// target_to, so that clients can word the reason themselves.
+	Params        map[string]string `protobuf:"bytes,3,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecommendationReason) Reset() {
+	*x = RecommendationReason{}
+	mi := &file_stock_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendationReason) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendationReason) ProtoMessage() {}
+
+func (x *RecommendationReason) ProtoReflect() protoreflect.Message {
+	mi := &file_stock_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendationReason.ProtoReflect.Descriptor instead.
+func (*RecommendationReason) Descriptor() ([]byte, []int) {
+	return file_stock_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RecommendationReason) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *RecommendationReason) GetDetails() string {
+	if x != nil {
+		return x.Details
+	}
+	return ""
+}
+
+func (x *RecommendationReason) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+var File_stock_proto protoreflect.FileDescriptor
+
+const file_stock_proto_rawDesc = "" +
+	"\n" +
+	"\vstock.proto\x12\vstockify.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe8\x02\n" +
+	"\x05Stock\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x16\n" +
+	"\x06ticker\x18\x02 \x01(\tR\x06ticker\x12\x18\n" +
+	"\acompany\x18\x03 \x01(\tR\acompany\x12\x1c\n" +
+	"\tbrokerage\x18\x04 \x01(\tR\tbrokerage\x12\x16\n" +
+	"\x06action\x18\x05 \x01(\tR\x06action\x12$\n" +
+	"\vrating_from\x18\x06 \x01(\tH\x00R\n" +
+	"ratingFrom\x88\x01\x01\x12\x1b\n" +
+	"\trating_to\x18\a \x01(\tR\bratingTo\x12$\n" +
+	"\vtarget_from\x18\b \x01(\x01H\x01R\n" +
+	"targetFrom\x88\x01\x01\x12 \n" +
+	"\ttarget_to\x18\t \x01(\x01H\x02R\btargetTo\x88\x01\x01\x12.\n" +
+	"\x04time\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x04timeB\x0e\n" +
+	"\f_rating_fromB\x0e\n" +
+	"\f_target_fromB\f\n" +
+	"\n" +
+	"_target_to\"\xbf\x01\n" +
+	"\x11ListStocksRequest\x12\x16\n" +
+	"\x06search\x18\x01 \x01(\tR\x06search\x12\x17\n" +
+	"\asort_by\x18\x02 \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\x03 \x01(\tR\tsortOrder\x12\x12\n" +
+	"\x04page\x18\x04 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\x12)\n" +
+	"\x10include_archived\x18\x06 \x01(\bR\x0fincludeArchived\"\xb3\x01\n" +
+	"\x12ListStocksResponse\x12*\n" +
+	"\x06stocks\x18\x01 \x03(\v2\x12.stockify.v1.StockR\x06stocks\x12\x1f\n" +
+	"\vtotal_items\x18\x02 \x01(\x03R\n" +
+	"totalItems\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1f\n" +
+	"\vtotal_pages\x18\x05 \x01(\x05R\n" +
+	"totalPages\"T\n" +
+	"\x0fGetStockRequest\x12\x16\n" +
+	"\x06ticker\x18\x01 \x01(\tR\x06ticker\x12)\n" +
+	"\x10include_archived\x18\x02 \x01(\bR\x0fincludeArchived\"1\n" +
+	"\x19GetRecommendationsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"c\n" +
+	"\x1aGetRecommendationsResponse\x12E\n" +
+	"\x0frecommendations\x18\x01 \x03(\v2\x1b.stockify.v1.RecommendationR\x0frecommendations\"\x8d\x01\n" +
+	"\x0eRecommendation\x12(\n" +
+	"\x05stock\x18\x01 \x01(\v2\x12.stockify.v1.StockR\x05stock\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12;\n" +
+	"\areasons\x18\x03 \x03(\v2!.stockify.v1.RecommendationReasonR\areasons\"\xc6\x01\n" +
+	"\x14RecommendationReason\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
+	"\adetails\x18\x02 \x01(\tR\adetails\x12E\n" +
+	"\x06params\x18\x03 \x03(\v2-.stockify.v1.RecommendationReason.ParamsEntryR\x06params\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\x82\x02\n" +
+	"\fStockService\x12M\n" +
+	"\n" +
+	"ListStocks\x12\x1e.stockify.v1.ListStocksRequest\x1a\x1f.stockify.v1.ListStocksResponse\x12<\n" +
+	"\bGetStock\x12\x1c.stockify.v1.GetStockRequest\x1a\x12.stockify.v1.Stock\x12e\n" +
+	"\x12GetRecommendations\x12&.stockify.v1.GetRecommendationsRequest\x1a'.stockify.v1.GetRecommendationsResponseB#Z!stockify/internal/api/rpc/stockpbb\x06proto3"
+
+var (
+	file_stock_proto_rawDescOnce sync.Once
+	file_stock_proto_rawDescData []byte
+)
+
+func file_stock_proto_rawDescGZIP() []byte {
+	file_stock_proto_rawDescOnce.Do(func() {
+		file_stock_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_stock_proto_rawDesc), len(file_stock_proto_rawDesc)))
+	})
+	return file_stock_proto_rawDescData
+}
+
+var file_stock_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_stock_proto_goTypes = []any{
+	(*Stock)(nil),                      // 0: stockify.v1.Stock
+	(*ListStocksRequest)(nil),          // 1: stockify.v1.ListStocksRequest
+	(*ListStocksResponse)(nil),         // 2: stockify.v1.ListStocksResponse
+	(*GetStockRequest)(nil),            // 3: stockify.v1.GetStockRequest
+	(*GetRecommendationsRequest)(nil),  // 4: stockify.v1.GetRecommendationsRequest
+	(*GetRecommendationsResponse)(nil), // 5: stockify.v1.GetRecommendationsResponse
+	(*Recommendation)(nil),             // 6: stockify.v1.Recommendation
+	(*RecommendationReason)(nil),       // 7: stockify.v1.RecommendationReason
+	nil,                                // 8: stockify.v1.RecommendationReason.ParamsEntry
+	(*timestamppb.Timestamp)(nil),      // 9: google.protobuf.Timestamp
+}
+var file_stock_proto_depIdxs = []int32{
+	9, // 0: stockify.v1.Stock.time:type_name -> google.protobuf.Timestamp
+	0, // 1: stockify.v1.ListStocksResponse.stocks:type_name -> stockify.v1.Stock
+	6, // 2: stockify.v1.GetRecommendationsResponse.recommendations:type_name -> stockify.v1.Recommendation
+	0, // 3: stockify.v1.Recommendation.stock:type_name -> stockify.v1.Stock
+	7, // 4: stockify.v1.Recommendation.reasons:type_name -> stockify.v1.RecommendationReason
+	8, // 5: stockify.v1.RecommendationReason.params:type_name -> stockify.v1.RecommendationReason.ParamsEntry
+	1, // 6: stockify.v1.StockService.ListStocks:input_type -> stockify.v1.ListStocksRequest
+	3, // 7: stockify.v1.StockService.GetStock:input_type -> stockify.v1.GetStockRequest
+	4, // 8: stockify.v1.StockService.GetRecommendations:input_type -> stockify.v1.GetRecommendationsRequest
+	2, // 9: stockify.v1.StockService.ListStocks:output_type -> stockify.v1.ListStocksResponse
+	0, // 10: stockify.v1.StockService.GetStock:output_type -> stockify.v1.Stock
+	5, // 11: stockify.v1.StockService.GetRecommendations:output_type -> stockify.v1.GetRecommendationsResponse
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_stock_proto_init() }
+func file_stock_proto_init() {
+	if File_stock_proto != nil {
+		return
+	}
+	file_stock_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stock_proto_rawDesc), len(file_stock_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_stock_proto_goTypes,
+		DependencyIndexes: file_stock_proto_depIdxs,
+		MessageInfos:      file_stock_proto_msgTypes,
+	}.Build()
+	File_stock_proto = out.File
+	file_stock_proto_goTypes = nil
+	file_stock_proto_depIdxs = nil
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"stockify/internal/config"
+	"strconv"
+
+	"github.com/go-chi/cors"
+)
+
+// corsHeaders are the request headers browsers may send cross-origin.
+var corsHeaders = []string{"Accept", "Accept-Language", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With", "Cache-Control", "Pragma", "Expires", "X-API-Key", "If-None-Match", "If-Modified-Since", "Last-Event-ID"}
+
+// newCORS returns the middleware applying cfg, or nil when no origin is
+// allowed, in which case no CORS headers should be sent at all.
+func newCORS(cfg config.CORSConfig) func(http.Handler) http.Handler {
+	if len(cfg.AllowedOrigins) == 0 {
+		return nil
+	}
+
+	return cors.New(cors.Options{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   corsHeaders,
+		ExposedHeaders:   []string{"Link", "ETag", "Last-Modified", "Content-Language"},
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           300,
+	}).Handler
+}
+
+// securityHeaders adds the configured security headers to every response,
+// leaving out those with an empty value.
+func securityHeaders(cfg config.SecurityHeadersConfig) func(http.Handler) http.Handler {
+	headers := make(map[string]string)
+
+	if cfg.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		headers["Strict-Transport-Security"] = hsts
+	}
+	for name, value := range map[string]string{
+		"Content-Security-Policy": cfg.ContentSecurityPolicy,
+		"X-Content-Type-Options":  cfg.ContentTypeOptions,
+		"Referrer-Policy":         cfg.ReferrerPolicy,
+	} {
+		if value != "" {
+			headers[name] = value
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range headers {
+				w.Header().Set(name, value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_CORS(t *testing.T) {
+	router := newTestRouter(t, &config.Config{CORS: config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowedMethods:   []string{"GET"},
+		AllowCredentials: true,
+	}}, nil)
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://pr-42.preview.example.com", true},
+		{"https://preview.example.com", false},
+		{"https://evil.example.org", false},
+		{"http://localhost:5173", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/api/v1/stocks", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if tt.allowed {
+				assert.Equal(t, tt.origin, rec.Header().Get("Access-Control-Allow-Origin"))
+				assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+			} else {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+			}
+		})
+	}
+}
+
+func TestRouter_CORSDisabledWithoutOrigins(t *testing.T) {
+	router := newTestRouter(t, &config.Config{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestRouter_SecurityHeaders(t *testing.T) {
+	router := newTestRouter(t, &config.Config{SecurityHeaders: config.SecurityHeadersConfig{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ContentSecurityPolicy: "default-src 'none'",
+		ContentTypeOptions:    "nosniff",
+	}}, nil)
+
+	for _, target := range []string{"/health", "/api/v1/stocks", "/api/v1/unknown"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, "max-age=31536000; includeSubDomains", rec.Header().Get("Strict-Transport-Security"), target)
+		assert.Equal(t, "default-src 'none'", rec.Header().Get("Content-Security-Policy"), target)
+		assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"), target)
+		assert.NotContains(t, rec.Header(), "Referrer-Policy", "empty values leave the header out")
+	}
+}
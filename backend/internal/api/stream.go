@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/services"
+	"stockify/internal/stream"
+	"strconv"
+	"time"
+)
+
+const (
+	// streamReplayBatch is how many stored events are read at a time when a
+	// client resumes from a Last-Event-ID.
+	streamReplayBatch = 500
+	// streamHeartbeat is how often an idle stream sends a comment, so that
+	// proxies do not close the connection.
+	streamHeartbeat = 30 * time.Second
+	// longWriteTimeout bounds each write of a long-lived response, such as
+	// an event stream or an export, which as a whole may last longer than
+	// the server's WriteTimeout.
+	longWriteTimeout = time.Minute
+)
+
+// extendWriteDeadline gives the next writes of a long-lived response
+// longWriteTimeout to complete, so that the response outlives the server's
+// WriteTimeout while a client that stops reading is still cut off. Writers
+// that cannot set deadlines, as in tests, are left as they are.
+func extendWriteDeadline(rc *http.ResponseController) {
+	_ = rc.SetWriteDeadline(time.Now().Add(longWriteTimeout))
+}
+
+// StreamHandler pushes newly created rating events to clients as
+// Server-Sent Events.
+type StreamHandler struct {
+	stockService *services.StockService
+	broker       *stream.Broker
+	heartbeat    time.Duration
+}
+
+func NewStreamHandler(ss *services.StockService, broker *stream.Broker) *StreamHandler {
+	return &StreamHandler{stockService: ss, broker: broker, heartbeat: streamHeartbeat}
+}
+
+// StreamEvents sends every new event matching the optional ticker, brokerage
+// and action query parameters. Each event carries its stock ID as the SSE id;
+// a client reconnecting with a Last-Event-ID header (or last_event_id query
+// parameter, for EventSource's first connection) first receives the stored
+// events it missed.
+func (h *StreamHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	filter := stream.Filter{
+		Ticker:    queryParams.Get("ticker"),
+		Brokerage: queryParams.Get("brokerage"),
+		Action:    queryParams.Get("action"),
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = queryParams.Get("last_event_id")
+	}
+
+	var lastID uint64
+	if lastEventID != "" {
+		var err error
+		lastID, err = strconv.ParseUint(lastEventID, 10, 0)
+		if err != nil {
+			respondWithValidationError(w, r, i18n.MsgInvalidLastEventID, newFieldError("last_event_id", i18n.MsgFieldEventID, nil))
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, r, http.StatusInternalServerError, ErrCodeInternal, i18n.MsgStreamingNotSupported)
+		return
+	}
+
+	// Subscribing before replaying ensures no event created meanwhile is
+	// lost; those already replayed are skipped below by their ID.
+	sub := h.broker.Subscribe(filter)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	rc := http.NewResponseController(w)
+	extendWriteDeadline(rc)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	lastSent := uint(lastID)
+
+	if lastEventID != "" {
+		for {
+			stocks, err := h.stockService.ListStocksAfter(ctx, lastSent, streamReplayBatch)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Error en ListStocksAfter service al reanudar el stream", "last_event_id", lastID, "error", err)
+				return
+			}
+
+			for _, stock := range stocks {
+				if filter.Matches(stock) {
+					extendWriteDeadline(rc)
+					if err := writeStreamEvent(w, stock); err != nil {
+						return
+					}
+				}
+				lastSent = stock.ID
+			}
+			flusher.Flush()
+
+			if len(stocks) < streamReplayBatch {
+				break
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			extendWriteDeadline(rc)
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case stock, ok := <-sub.Events():
+			if !ok {
+				select {
+				case <-h.broker.Done():
+					// The server is shutting down; the client reconnects
+					// to another instance with its Last-Event-ID.
+				default:
+					slog.WarnContext(r.Context(), "Stream de eventos cerrado: el cliente no consume eventos a tiempo", "remote_addr", r.RemoteAddr)
+				}
+				return
+			}
+			if stock.ID <= lastSent {
+				continue
+			}
+			extendWriteDeadline(rc)
+			if err := writeStreamEvent(w, stock); err != nil {
+				return
+			}
+			lastSent = stock.ID
+			flusher.Flush()
+		}
+	}
+}
+
+func writeStreamEvent(w io.Writer, stock core.Stock) error {
+	data, err := json.Marshal(newStockResponse(stock))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: stock\ndata: %s\n\n", stock.ID, data)
+	return err
+}
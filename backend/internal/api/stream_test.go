@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/core"
+	"stockify/internal/services"
+	"stockify/internal/store"
+	"stockify/internal/stream"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamServer(t *testing.T) (*httptest.Server, *stream.Broker, *store.MemoryStore) {
+	t.Helper()
+
+	memoryStore := store.NewMemoryStore([]core.Stock{
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker A", Action: "upgraded by", RatingTo: "Buy", Time: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{Ticker: "MSFT", Company: "Microsoft Corp.", Brokerage: "Broker B", Action: "initiated by", RatingTo: "Outperform", Time: time.Date(2025, 5, 2, 12, 0, 0, 0, time.UTC)},
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker C", Action: "downgraded by", RatingTo: "Hold", Time: time.Date(2025, 5, 3, 12, 0, 0, 0, time.UTC)},
+	})
+	broker := stream.NewBroker()
+
+	server := httptest.NewServer(http.HandlerFunc(NewStreamHandler(services.NewStockService(memoryStore), broker).StreamEvents))
+	t.Cleanup(server.Close)
+
+	return server, broker, memoryStore
+}
+
+// readEventIDs reads the ids of the next n events of an SSE stream.
+func readEventIDs(t *testing.T, body *bufio.Reader, n int) []string {
+	t.Helper()
+
+	var ids []string
+	for len(ids) < n {
+		line, err := body.ReadString('\n')
+		require.NoError(t, err)
+		if id, ok := strings.CutPrefix(strings.TrimSpace(line), "id: "); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestStreamEvents_ResumesAndFollowsLiveEvents(t *testing.T) {
+	server, broker, memoryStore := newStreamServer(t)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/stream/events?ticker=aapl", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body := bufio.NewReader(resp.Body)
+
+	assert.Equal(t, []string{"3"}, readEventIDs(t, body, 1), "stored AAPL events after 1")
+
+	msft := core.Stock{Ticker: "MSFT", Brokerage: "Broker A", Action: "upgraded by", Time: time.Now()}
+	apple := core.Stock{Ticker: "AAPL", Brokerage: "Broker A", Action: "target raised by", Time: time.Now()}
+	require.NoError(t, memoryStore.CreateStocks(t.Context(), &msft))
+	require.NoError(t, memoryStore.CreateStocks(t.Context(), &apple))
+	broker.Publish([]core.Stock{msft, apple})
+
+	assert.Equal(t, []string{"5"}, readEventIDs(t, body, 1), "live AAPL event")
+}
+
+func TestStreamEvents_InvalidLastEventID(t *testing.T) {
+	server, _, _ := newStreamServer(t)
+
+	resp, err := http.Get(server.URL + "/api/v1/stream/events?last_event_id=abc")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+}
+
+func TestStreamEvents_OutlivesWriteTimeoutAndEndsOnShutdown(t *testing.T) {
+	memoryStore := store.NewMemoryStore(nil)
+	broker := stream.NewBroker()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(NewStreamHandler(services.NewStockService(memoryStore), broker).StreamEvents))
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/v1/stream/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Eventually(t, func() bool { return broker.Subscribers() == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+
+	apple := core.Stock{Ticker: "AAPL", Brokerage: "Broker A", Action: "upgraded by", RatingTo: "Buy"}
+	require.NoError(t, memoryStore.CreateStocks(context.Background(), &apple))
+	broker.Publish([]core.Stock{apple})
+
+	body := bufio.NewReader(resp.Body)
+	assert.Equal(t, []string{"1"}, readEventIDs(t, body, 1), "events keep flowing past the server's WriteTimeout")
+
+	broker.Close()
+	_, err = io.ReadAll(body)
+	assert.NoError(t, err, "the stream ends cleanly when the broker closes")
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
+	"stockify/internal/logging"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,32 +15,249 @@ type Config struct {
 	DatabaseURL   string
 	StockAPIToken string
 	ServerPort    string
+	QueryTimeout  time.Duration
+	CacheSize     int
+	CacheTTL      time.Duration
+	// CacheCheckInterval is how often the database is checked for changes
+	// made by other processes, such as the datasync command, to invalidate
+	// the query cache. Zero leaves them to expire with CacheTTL.
+	CacheCheckInterval time.Duration
+	// StreamPollInterval is how often the store is checked for new events
+	// to push to stream clients. Zero disables the stream feed.
+	StreamPollInterval time.Duration
+	AdminAPIKeys       map[string]string
+	// HTTPCacheMaxAge is the max-age sent in the Cache-Control header of
+	// cacheable responses. Zero makes clients revalidate every time.
+	HTTPCacheMaxAge time.Duration
+	// RetentionMonths is how old, in months, an event must be before the
+	// retention policy archives it. Zero disables the policy.
+	RetentionMonths int
+	// GRPCPort is the address of the gRPC server, which listens apart from
+	// the HTTP API.
+	GRPCPort string
+	// CORS decides which browser origins may call the HTTP API.
+	CORS CORSConfig
+	// SecurityHeaders are added to every HTTP response.
+	SecurityHeaders SecurityHeadersConfig
+	// DataStaleAfter is how long after the last successful sync the data
+	// health check reports the data as degraded. Zero disables the check.
+	DataStaleAfter time.Duration
+	// HTTPReadTimeout, HTTPWriteTimeout and HTTPIdleTimeout bound how long
+	// the HTTP server waits to read a request, to write a response and for
+	// the next request on a kept-alive connection. Event streams and exports
+	// extend their write deadline as they go.
+	HTTPReadTimeout  time.Duration
+	HTTPWriteTimeout time.Duration
+	HTTPIdleTimeout  time.Duration
+	// ShutdownTimeout is how long the server waits for in-flight requests
+	// to finish after SIGTERM or SIGINT before closing them.
+	ShutdownTimeout time.Duration
+	// LogLevel is the minimum level of the lines logged: debug, info, warn
+	// or error.
+	LogLevel slog.Level
 }
 
+// CORSConfig is the cross-origin policy of the HTTP API. Without allowed
+// origins no CORS headers are sent, so browsers only allow same-origin
+// requests.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to call the API, such as
+	// "https://app.example.com". An origin may contain one wildcard, as in
+	// "https://*.example.com" for every subdomain, and "*" allows any origin.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowCredentials bool
+}
+
+// SecurityHeadersConfig holds the values of the security headers. An empty
+// value, or a zero HSTSMaxAge, leaves its header out.
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge is the max-age of Strict-Transport-Security. Browsers
+	// ignore the header over plain HTTP, so only set it when the API is
+	// served over HTTPS.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	ContentSecurityPolicy string
+	ContentTypeOptions    string
+	ReferrerPolicy        string
+}
+
+// Load reads the configuration from the environment (and an optional .env
+// file), exiting if DATABASE_URL or STOCK_API_TOKEN is missing.
 func Load() *Config {
+	cfg := load()
+
+	if cfg.DatabaseURL == "" {
+		logging.Fatal("DATABASE_URL environment variable not set")
+	}
+
+	if cfg.StockAPIToken == "" {
+		logging.Fatal("STOCK_API_TOKEN environment variable not set")
+	}
+
+	return cfg
+}
+
+// LoadDemo reads the configuration for the server's demo mode, which needs
+// neither a database nor the external API token.
+func LoadDemo() *Config {
+	return load()
+}
+
+func load() *Config {
 	err := godotenv.Load()
 	if err != nil {
-		log.Println("Advertencia: No se pudo cargar el archivo .env.")
+		slog.Warn("No se pudo cargar el archivo .env", "error", err)
 	}
 
 	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Fatal("ERROR: DATABASE_URL environment variable not set.")
-	}
-
 	apiToken := os.Getenv("STOCK_API_TOKEN")
-	if apiToken == "" {
-		log.Fatal("ERROR: STOCK_API_TOKEN environment variable not set.")
-	}
 
 	port := os.Getenv("SERVER_PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+
 	return &Config{
-		DatabaseURL:   dbURL,
-		StockAPIToken: apiToken,
-		ServerPort:    ":" + port,
+		DatabaseURL:        dbURL,
+		StockAPIToken:      apiToken,
+		ServerPort:         ":" + port,
+		GRPCPort:           ":" + grpcPort,
+		QueryTimeout:       durationEnv("DB_QUERY_TIMEOUT", 5*time.Second),
+		CacheSize:          intEnv("CACHE_SIZE", 500),
+		CacheTTL:           durationEnv("CACHE_TTL", 5*time.Minute),
+		CacheCheckInterval: durationEnv("CACHE_CHECK_INTERVAL", 10*time.Second),
+		StreamPollInterval: durationEnv("STREAM_POLL_INTERVAL", 2*time.Second),
+		AdminAPIKeys:       adminAPIKeysEnv("ADMIN_API_KEYS"),
+		RetentionMonths:    intEnv("RETENTION_MONTHS", 0),
+		HTTPCacheMaxAge:    durationEnv("HTTP_CACHE_MAX_AGE", 0),
+		CORS: CORSConfig{
+			AllowedOrigins:   listEnv("CORS_ALLOWED_ORIGINS", []string{"http://localhost:5173", "http://127.0.0.1:5173", "http://localhost:3000", "http://127.0.0.1:3000"}),
+			AllowedMethods:   listEnv("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+			AllowCredentials: boolEnv("CORS_ALLOW_CREDENTIALS", true),
+		},
+		SecurityHeaders: SecurityHeadersConfig{
+			HSTSMaxAge:            durationEnv("HSTS_MAX_AGE", 0),
+			HSTSIncludeSubdomains: boolEnv("HSTS_INCLUDE_SUBDOMAINS", false),
+			ContentSecurityPolicy: stringEnv("CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'"),
+			ContentTypeOptions:    stringEnv("X_CONTENT_TYPE_OPTIONS", "nosniff"),
+			ReferrerPolicy:        stringEnv("REFERRER_POLICY", "no-referrer"),
+		},
+		DataStaleAfter:   durationEnv("DATA_STALE_AFTER", 48*time.Hour),
+		HTTPReadTimeout:  durationEnv("HTTP_READ_TIMEOUT", 30*time.Second),
+		HTTPWriteTimeout: durationEnv("HTTP_WRITE_TIMEOUT", 30*time.Second),
+		HTTPIdleTimeout:  durationEnv("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		ShutdownTimeout:  durationEnv("SHUTDOWN_TIMEOUT", 20*time.Second),
+		LogLevel:         levelEnv("LOG_LEVEL", slog.LevelInfo),
+	}
+}
+
+// adminAPIKeysEnv parses a comma-separated list of name:key pairs into a map
+// from key to the name of its holder.
+func adminAPIKeysEnv(name string) map[string]string {
+	keys := make(map[string]string)
+
+	for _, pair := range strings.Split(os.Getenv(name), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		holder, key, ok := strings.Cut(pair, ":")
+		if !ok || strings.TrimSpace(holder) == "" || strings.TrimSpace(key) == "" {
+			logging.Fatal("Variable de entorno inválida, se esperaba nombre:clave separados por comas", "name", name)
+		}
+
+		keys[strings.TrimSpace(key)] = strings.TrimSpace(holder)
+	}
+
+	return keys
+}
+
+// stringEnv returns the variable name, or defaultValue when it is not set.
+// Setting it to an empty value yields the empty string.
+func stringEnv(name, defaultValue string) string {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue
+	}
+	return strings.TrimSpace(value)
+}
+
+// listEnv parses a comma-separated list, returning defaultValue when the
+// variable is not set and an empty list when it is set but empty.
+func listEnv(name string, defaultValue []string) []string {
+	raw, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue
+	}
+
+	values := []string{}
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func boolEnv(name string, defaultValue bool) bool {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		logging.Fatal("Variable de entorno inválida", "name", name, "value", raw, "error", err)
+	}
+
+	return value
+}
+
+func durationEnv(name string, defaultValue time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return defaultValue
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		logging.Fatal("Variable de entorno inválida", "name", name, "value", raw, "error", err)
+	}
+
+	return value
+}
+
+func intEnv(name string, defaultValue int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		logging.Fatal("Variable de entorno inválida", "name", name, "value", raw, "error", err)
 	}
+
+	return value
+}
+
+func levelEnv(name string, defaultValue slog.Level) slog.Level {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return defaultValue
+	}
+
+	var value slog.Level
+	if err := value.UnmarshalText([]byte(raw)); err != nil {
+		logging.Fatal("Variable de entorno inválida", "name", name, "value", raw, "error", err)
+	}
+
+	return value
 }
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Audit actions recorded for changes to rating events. Soft deletes and
+// purges are both deletes; restores are updates. Archive entries record the
+// events the retention policy moved to the archive.
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionArchive = "archive"
+)
+
+// Kinds of actors that can change rating events.
+const (
+	ActorTypeSync   = "sync"
+	ActorTypeAPIKey = "api_key"
+	ActorTypeSystem = "system"
+)
+
+// Actor identifies who performed a change: a sync run by its run ID, an admin
+// by the holder name of their API key, or the system itself.
+type Actor struct {
+	Type string
+	ID   string
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a copy of ctx carrying actor, which stores attach to the
+// audit entries of the changes they make.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx, or a system actor if none
+// was set.
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorContextKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{Type: ActorTypeSystem, ID: "system"}
+}
+
+// AuditEntry records a single change to a rating event with the JSON state of
+// the event before and after it. Before is null for creates and After is null
+// for permanent deletes and archives.
+type AuditEntry struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	ActorType string          `json:"actor_type"`
+	ActorID   string          `json:"actor_id"`
+	Action    string          `json:"action"`
+	StockID   uint            `json:"stock_id"`
+	Ticker    string          `json:"ticker"`
+	Before    json.RawMessage `gorm:"column:before_state" json:"before"`
+	After     json.RawMessage `gorm:"column:after_state" json:"after"`
+}
+
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+// NewAuditEntry builds the entry for a change made by the actor in ctx.
+// before or after may be nil.
+func NewAuditEntry(ctx context.Context, action string, before, after *Stock) (AuditEntry, error) {
+	actor := ActorFromContext(ctx)
+	entry := AuditEntry{
+		CreatedAt: time.Now().UTC(),
+		ActorType: actor.Type,
+		ActorID:   actor.ID,
+		Action:    action,
+	}
+
+	var err error
+
+	if before != nil {
+		entry.StockID, entry.Ticker = before.ID, before.Ticker
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return AuditEntry{}, err
+		}
+	}
+
+	if after != nil {
+		entry.StockID, entry.Ticker = after.ID, after.Ticker
+		if entry.After, err = json.Marshal(after); err != nil {
+			return AuditEntry{}, err
+		}
+	}
+
+	return entry, nil
+}
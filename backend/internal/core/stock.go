@@ -8,7 +8,7 @@ import (
 
 type Stock struct {
 	gorm.Model
-	Ticker     string    `gorm:"not null;index" json:"ticker"`
+	Ticker     string    `gorm:"not null;index;index:idx_stocks_ticker_time,priority:1" json:"ticker"`
 	Company    string    `gorm:"not null" json:"company"`
 	Brokerage  string    `gorm:"not null" json:"brokerage"`
 	Action     string    `gorm:"not null" json:"action"`
@@ -16,5 +16,12 @@ type Stock struct {
 	RatingFrom *string   `json:"rating_from,omitempty"`
 	TargetTo   *float64  `gorm:"type:decimal(10,2)" json:"target_to,omitempty"`
 	TargetFrom *float64  `gorm:"type:decimal(10,2)" json:"target_from,omitempty"`
-	Time       time.Time `json:"time"`
+	Time       time.Time `gorm:"index:idx_stocks_ticker_time,priority:2" json:"time"`
+}
+
+// LatestStock is the most recent rating event of a ticker together with the
+// number of events recorded for that ticker.
+type LatestStock struct {
+	Stock
+	EventCount int64 `json:"event_count"`
 }
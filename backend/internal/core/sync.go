@@ -0,0 +1,41 @@
+package core
+
+import "time"
+
+// SyncRun records a run of the data sync with the external API, so that
+// health checks and metrics can tell how the sync is doing even when it runs
+// in another process. Runs cut short by shutdown are not recorded.
+type SyncRun struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	RunID      string    `json:"run_id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Pages      int       `json:"pages"`
+	Created    int       `json:"created"`
+	// Skipped counts the items not stored because the event already was,
+	// even if soft-deleted or archived.
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+	// ErrorMessage is why the run failed, empty if it succeeded.
+	ErrorMessage string `json:"error_message"`
+}
+
+func (SyncRun) TableName() string {
+	return "sync_runs"
+}
+
+// Succeeded reports whether the run finished without error.
+func (run SyncRun) Succeeded() bool {
+	return run.ErrorMessage == ""
+}
+
+// SyncStats adds up every recorded sync run.
+type SyncStats struct {
+	Pages      int64
+	Created    int64
+	Skipped    int64
+	Failed     int64
+	FailedRuns int64
+	// LastSuccess is the last successful run, nil if there was none.
+	LastSuccess *SyncRun
+}
@@ -1,29 +1,73 @@
 package database
 
 import (
-	"log"
-	"stockify/internal/core"
+	"fmt"
+	"log/slog"
+	"stockify/internal/logging"
+	"strings"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// Supported SQL dialects, matching the names reported by the GORM dialectors.
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// dialectorFor picks the GORM dialector from the scheme of dsn:
+// postgres:// and postgresql:// select CockroachDB/PostgreSQL, while
+// sqlite://<path> selects an embedded SQLite file.
+func dialectorFor(dsn string) (gorm.Dialector, error) {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return postgres.Open(dsn), nil
+	case strings.HasPrefix(dsn, "sqlite://"):
+		path := strings.TrimPrefix(dsn, "sqlite://")
+		if path == "" {
+			return nil, fmt.Errorf("sqlite URL %q has no file path", dsn)
+		}
+		if !strings.Contains(path, "_pragma=busy_timeout") {
+			separator := "?"
+			if strings.Contains(path, "?") {
+				separator = "&"
+			}
+			path += separator + "_pragma=busy_timeout(5000)"
+		}
+		return sqlite.Open(path), nil
+	default:
+		return nil, fmt.Errorf("unsupported DATABASE_URL scheme in %q", dsn)
+	}
+}
+
 func Connect(dsn string) *gorm.DB {
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	dialector, err := dialectorFor(dsn)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		logging.Fatal("Failed to configure database", "error", err)
 	}
-	log.Println("Database connection successful!")
 
-	if err := db.Exec("SELECT 1").Error; err != nil {
-		log.Fatal("Database ping failed:", err)
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: slogLogger{}})
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "error", err)
 	}
-	log.Println("Database ping successful!")
+	slog.Info("Database connection successful", "dialect", db.Dialector.Name())
 
-	log.Println("Running database migrations...")
-	if err := db.AutoMigrate(&core.Stock{}); err != nil {
-		log.Fatal("Failed to migrate database:", err)
+	if err := db.Exec("SELECT 1").Error; err != nil {
+		logging.Fatal("Database ping failed", "error", err)
 	}
-	log.Println("Database migrations successful!")
+	slog.Info("Database ping successful")
+
 	return db
 }
+
+// Close closes the connection pool of db, waiting for the queries in progress
+// to finish.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is how long a query may take before it is logged as
+// slow.
+const slowQueryThreshold = 200 * time.Millisecond
+
+// slogLogger reports GORM's queries through log/slog, so that they carry
+// the attributes of their context, such as the request ID: failed queries
+// at error level, slow ones at warn level and the rest at debug level.
+type slogLogger struct{}
+
+// LogMode is a no-op, as the level is decided by the slog handler.
+func (l slogLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (slogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (slogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (slogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+
+	var level slog.Level
+	var msg string
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level, msg = slog.LevelError, "Query failed"
+	case elapsed > slowQueryThreshold:
+		level, msg = slog.LevelWarn, "Slow query"
+	default:
+		level, msg = slog.LevelDebug, "Query"
+	}
+
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []any{"sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds()}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	slog.Log(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,271 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+// ErrPendingMigrations is returned by CheckSchema when the database is behind
+// the migrations embedded in the binary.
+var ErrPendingMigrations = errors.New("database schema has pending migrations")
+
+var migrationFileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change read from the embedded SQL files
+// named migrations/<dialect>/<version>_<name>.up.sql and .down.sql.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus describes a known migration and when it was applied. A nil
+// AppliedAt means the migration is pending.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// LoadMigrations returns the embedded migrations of dialect ordered by
+// version.
+func LoadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q: %w", dialect, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := migrationFileRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+
+		contents, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d used by %q and %q", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func ensureMigrationsTable(ctx context.Context, db *gorm.DB) error {
+	timestampType := "TIMESTAMPTZ"
+	if db.Dialector.Name() == DialectSQLite {
+		timestampType = "DATETIME"
+	}
+
+	return db.WithContext(ctx).Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at ` + timestampType + ` NOT NULL
+)`).Error
+}
+
+func appliedMigrations(ctx context.Context, db *gorm.DB) (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.WithContext(ctx).Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+// MigrateUp applies every pending migration in version order, each one in its
+// own transaction, and returns how many were applied.
+func MigrateUp(ctx context.Context, db *gorm.DB) (int, error) {
+	migrations, err := LoadMigrations(db.Dialector.Name())
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return 0, fmt.Errorf("creating schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		slog.InfoContext(ctx, "Applying migration", "version", migration.Version, "name", migration.Name)
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first, and
+// returns how many were reverted.
+func MigrateDown(ctx context.Context, db *gorm.DB, steps int) (int, error) {
+	migrations, err := LoadMigrations(db.Dialector.Name())
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return 0, fmt.Errorf("creating schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		slog.InfoContext(ctx, "Reverting migration", "version", migration.Version, "name", migration.Name)
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+
+			return tx.Delete(&schemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Status reports every embedded migration and whether it has been applied.
+func Status(ctx context.Context, db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]schemaMigration{}
+	if db.WithContext(ctx).Migrator().HasTable(&schemaMigration{}) {
+		applied, err = appliedMigrations(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// CheckSchema verifies, without changing anything, that every embedded
+// migration has been applied. It wraps ErrPendingMigrations otherwise.
+func CheckSchema(ctx context.Context, db *gorm.DB) error {
+	statuses, err := Status(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%d_%s", status.Version, status.Name))
+		}
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %v", ErrPendingMigrations, pending)
+	}
+
+	return nil
+}
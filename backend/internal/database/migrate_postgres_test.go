@@ -0,0 +1,39 @@
+package database
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// postgresTestURLEnv names the variable holding the URL of a throwaway
+// PostgreSQL or CockroachDB database for the migration integration test.
+// The test applies and reverts every migration, so the database must be
+// empty and must not hold data worth keeping.
+const postgresTestURLEnv = "TEST_POSTGRES_URL"
+
+// TestMigrateUpDownStatus_Postgres runs the migration round trip against a
+// real PostgreSQL or CockroachDB server, checking that the postgres scripts
+// apply and revert cleanly inside the migration transactions, which
+// CockroachDB restricts for schema changes. It is skipped unless
+// TEST_POSTGRES_URL is set, e.g. with the database of compose.yml:
+//
+//	TEST_POSTGRES_URL=postgresql://root@localhost:26257/stockify_test?sslmode=disable go test ./internal/database
+func TestMigrateUpDownStatus_Postgres(t *testing.T) {
+	dsn := os.Getenv(postgresTestURLEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", postgresTestURLEnv)
+	}
+
+	dialector, err := dialectorFor(dsn)
+	require.NoError(t, err)
+	require.Equal(t, DialectPostgres, dialector.Name(), "%s must be a postgres:// or postgresql:// URL", postgresTestURLEnv)
+
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: slogLogger{}})
+	require.NoError(t, err)
+	t.Cleanup(func() { Close(db) })
+
+	testMigrationRoundTrip(t, db)
+}
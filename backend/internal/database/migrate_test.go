@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	postgresMigrations, err := LoadMigrations(DialectPostgres)
+	require.NoError(t, err)
+
+	sqliteMigrations, err := LoadMigrations(DialectSQLite)
+	require.NoError(t, err)
+	require.Len(t, sqliteMigrations, len(postgresMigrations), "every dialect must ship the same migrations")
+
+	for i := range postgresMigrations {
+		assert.Equal(t, postgresMigrations[i].Version, sqliteMigrations[i].Version)
+		assert.Equal(t, postgresMigrations[i].Name, sqliteMigrations[i].Name)
+	}
+
+	migrations := postgresMigrations
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_stocks", migrations[0].Name)
+
+	for i, migration := range migrations {
+		assert.NotEmpty(t, migration.Up, "migration %d has no up script", migration.Version)
+		assert.NotEmpty(t, migration.Down, "migration %d has no down script", migration.Version)
+
+		if i > 0 {
+			assert.Greater(t, migration.Version, migrations[i-1].Version, "migrations must be sorted and unique")
+		}
+	}
+}
+
+func TestMigrateUpDownStatus(t *testing.T) {
+	db := Connect("sqlite://" + filepath.Join(t.TempDir(), "stockify.db"))
+	testMigrationRoundTrip(t, db)
+}
+
+// testMigrationRoundTrip applies every migration, reverts them one step and
+// then all at once, and applies them again, checking the recorded status at
+// each step. db must hold no schema.
+func testMigrationRoundTrip(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	ctx := context.Background()
+
+	assert.ErrorIs(t, CheckSchema(ctx, db), ErrPendingMigrations)
+
+	applied, err := MigrateUp(ctx, db)
+	require.NoError(t, err)
+	assert.Greater(t, applied, 0)
+	assert.NoError(t, CheckSchema(ctx, db))
+	assert.True(t, db.Migrator().HasTable("stocks"))
+
+	applied, err = MigrateUp(ctx, db)
+	require.NoError(t, err)
+	assert.Zero(t, applied, "a second run must be a no-op")
+
+	reverted, err := MigrateDown(ctx, db, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+
+	statuses, err := Status(ctx, db)
+	require.NoError(t, err)
+	assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.ErrorIs(t, CheckSchema(ctx, db), ErrPendingMigrations)
+
+	_, err = MigrateDown(ctx, db, len(statuses))
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable("stocks"))
+
+	// The down scripts must leave nothing behind that would make the up
+	// scripts fail a second time.
+	applied, err = MigrateUp(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, len(statuses), applied)
+
+	_, err = MigrateDown(ctx, db, len(statuses))
+	require.NoError(t, err)
+}
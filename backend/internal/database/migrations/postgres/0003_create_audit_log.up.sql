@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL,
+    actor_type TEXT NOT NULL,
+    actor_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    stock_id BIGINT NOT NULL,
+    ticker TEXT NOT NULL,
+    before_state JSONB,
+    after_state JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_stock_id ON audit_log (stock_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_type, actor_id);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS stocks_archive (
+    id BIGINT PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    ticker TEXT NOT NULL,
+    company TEXT NOT NULL,
+    brokerage TEXT NOT NULL,
+    action TEXT NOT NULL,
+    rating_to TEXT NOT NULL,
+    rating_from TEXT,
+    target_to DECIMAL(10,2),
+    target_from DECIMAL(10,2),
+    time TIMESTAMPTZ,
+    archived_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stocks_archive_ticker_time ON stocks_archive (ticker, time);
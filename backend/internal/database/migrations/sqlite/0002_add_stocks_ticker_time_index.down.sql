@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_stocks_ticker_time;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_stocks_ticker_time ON stocks (ticker, time);
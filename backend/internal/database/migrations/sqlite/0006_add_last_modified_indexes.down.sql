@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stocks_archive_archived_at;
+DROP INDEX IF EXISTS idx_stocks_updated_at;
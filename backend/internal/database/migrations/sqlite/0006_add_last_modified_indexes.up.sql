@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_stocks_updated_at ON stocks (updated_at);
+CREATE INDEX IF NOT EXISTS idx_stocks_archive_archived_at ON stocks_archive (archived_at);
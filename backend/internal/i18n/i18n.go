@@ -0,0 +1,118 @@
+// Package i18n holds the catalog of user-facing messages of the API, such as
+// error details and recommendation reasons, in every supported language.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Lang is a supported language, identified by its ISO 639-1 code.
+type Lang string
+
+const (
+	Spanish Lang = "es"
+	English Lang = "en"
+)
+
+// Default is the language used when the client does not ask for a supported
+// one, and the language of the messages logged or stored by the services.
+const Default = Spanish
+
+// Supported lists the languages of the catalog, Default first.
+var Supported = []Lang{Spanish, English}
+
+var matcher = language.NewMatcher([]language.Tag{language.Spanish, language.English})
+
+// Parse returns the supported language named by code, such as "en" or
+// "en-GB", and whether there is one.
+func Parse(code string) (Lang, bool) {
+	tag, err := language.Parse(strings.TrimSpace(code))
+	if err != nil {
+		return "", false
+	}
+
+	base, _ := tag.Base()
+	for _, lang := range Supported {
+		if base.String() == string(lang) {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the supported language that best matches an
+// Accept-Language header, falling back to Default.
+func Negotiate(acceptLanguage string) Lang {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return Supported[index]
+}
+
+type contextKey struct{}
+
+// WithLang returns a copy of ctx carrying lang.
+func WithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext returns the language stored by WithLang, or Default.
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(contextKey{}).(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// Key identifies a message of the catalog.
+type Key string
+
+// Params are the named values interpolated into a message, where each
+// {name} placeholder is replaced by the value of name. Floats are formatted
+// with two decimals.
+type Params map[string]interface{}
+
+// T returns the message key in lang, with params interpolated. Messages
+// missing in lang fall back to Default, and unknown keys to the key itself.
+func T(lang Lang, key Key, params Params) string {
+	translations := catalog[key]
+	message, ok := translations[lang]
+	if !ok {
+		message, ok = translations[Default]
+	}
+	if !ok {
+		message = string(key)
+	}
+
+	if len(params) == 0 {
+		return message
+	}
+
+	replacements := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", formatParam(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
+
+func formatParam(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
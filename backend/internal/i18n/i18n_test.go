@@ -0,0 +1,58 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog_IsComplete(t *testing.T) {
+	for key, translations := range catalog {
+		for _, lang := range Supported {
+			assert.NotEmpty(t, translations[lang], "%s has no %s message", key, lang)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           Lang
+	}{
+		{"", Spanish},
+		{"en", English},
+		{"en-US,en;q=0.9", English},
+		{"es-MX", Spanish},
+		{"fr, en;q=0.5, es;q=0.8", Spanish},
+		{"de", Spanish},
+		{"*", Spanish},
+		{"not a language;;", Spanish},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Negotiate(tt.acceptLanguage), "Accept-Language %q", tt.acceptLanguage)
+	}
+}
+
+func TestParse(t *testing.T) {
+	lang, ok := Parse("en-GB")
+	assert.True(t, ok)
+	assert.Equal(t, English, lang)
+
+	lang, ok = Parse("ES")
+	assert.True(t, ok)
+	assert.Equal(t, Spanish, lang)
+
+	_, ok = Parse("fr")
+	assert.False(t, ok)
+}
+
+func TestT(t *testing.T) {
+	params := Params{"target_from": 180.0, "target_to": 215.5}
+	assert.Equal(t, "Price target raised from $180.00 to $215.50", T(English, MsgReasonTargetIncreased, params))
+	assert.Equal(t, "Precio objetivo aumentado de $180.00 a $215.50", T(Spanish, MsgReasonTargetIncreased, params))
+	assert.Equal(t, "Recent rating event (last 3 months).", T(English, MsgReasonRecentEvent, Params{"months": 3}))
+
+	assert.Equal(t, "Stock no encontrado", T("pt", MsgStockNotFound, nil), "unknown languages fall back to Default")
+	assert.Equal(t, "missing.key", T(English, "missing.key", nil))
+}
@@ -0,0 +1,344 @@
+package i18n
+
+// Problem details returned by the API.
+const (
+	MsgRouteNotFound           Key = "error.route_not_found"
+	MsgMethodNotAllowed        Key = "error.method_not_allowed"
+	MsgInvalidAdminCredentials Key = "error.invalid_admin_credentials"
+	MsgInvalidLang             Key = "error.invalid_lang"
+	MsgInvalidView             Key = "error.invalid_view"
+	MsgInvalidFields           Key = "error.invalid_fields"
+	MsgInvalidFormat           Key = "error.invalid_format"
+	MsgInvalidSortBy           Key = "error.invalid_sort_by"
+	MsgInvalidSortOrder        Key = "error.invalid_sort_order"
+	MsgInvalidPage             Key = "error.invalid_page"
+	MsgInvalidPageSize         Key = "error.invalid_page_size"
+	MsgInvalidLimit            Key = "error.invalid_limit"
+	MsgInvalidID               Key = "error.invalid_id"
+	MsgInvalidDryRun           Key = "error.invalid_dry_run"
+	MsgInvalidAuditFilters     Key = "error.invalid_audit_filters"
+	MsgInvalidLastEventID      Key = "error.invalid_last_event_id"
+	MsgTickerRequired          Key = "error.ticker_required"
+	MsgMissingCSV              Key = "error.missing_csv"
+	MsgCSVTooLarge             Key = "error.csv_too_large"
+	MsgQueryTooLarge           Key = "error.query_too_large"
+	MsgStockNotFound           Key = "error.stock_not_found"
+	MsgDeletedStockNotFound    Key = "error.deleted_stock_not_found"
+	MsgListStocksFailed        Key = "error.list_stocks_failed"
+	MsgGetStockFailed          Key = "error.get_stock_failed"
+	MsgRecommendationsFailed   Key = "error.recommendations_failed"
+	MsgListBrokeragesFailed    Key = "error.list_brokerages_failed"
+	MsgExportFailed            Key = "error.export_failed"
+	MsgListDeletedStocksFailed Key = "error.list_deleted_stocks_failed"
+	MsgDeleteStockFailed       Key = "error.delete_stock_failed"
+	MsgRestoreStockFailed      Key = "error.restore_stock_failed"
+	MsgPurgeStockFailed        Key = "error.purge_stock_failed"
+	MsgAuditFailed             Key = "error.audit_failed"
+	MsgImportFailed            Key = "error.import_failed"
+	MsgInvalidImportFile       Key = "error.invalid_import_file"
+	MsgStreamingNotSupported   Key = "error.streaming_not_supported"
+	MsgInternalError           Key = "error.internal"
+)
+
+// Reasons of a rejected request parameter.
+const (
+	MsgFieldRequired          Key = "field.required"
+	MsgFieldRequiredMultipart Key = "field.required_multipart"
+	MsgFieldAllowedValues     Key = "field.allowed_values"
+	MsgFieldUnknownFields     Key = "field.unknown_fields"
+	MsgFieldPositiveInteger   Key = "field.positive_integer"
+	MsgFieldNumeric           Key = "field.numeric"
+	MsgFieldRange             Key = "field.range"
+	MsgFieldNonNegative       Key = "field.non_negative"
+	MsgFieldEventID           Key = "field.event_id"
+	MsgFieldBoolean           Key = "field.boolean"
+	MsgFieldRFC3339           Key = "field.rfc3339"
+)
+
+// Reasons a CSV file, or one of its rows, is rejected by the import.
+const (
+	MsgImportEmptyFile     Key = "import.empty_file"
+	MsgImportMalformedCSV  Key = "import.malformed_csv"
+	MsgImportUnknownColumn Key = "import.unknown_column"
+	MsgImportMissingColumn Key = "import.missing_column"
+	MsgImportColumnCount   Key = "import.column_count"
+	MsgImportRequired      Key = "import.required"
+	MsgImportInvalidAmount Key = "import.invalid_amount"
+	MsgImportInvalidTime   Key = "import.invalid_time"
+	MsgImportRepeatedEvent Key = "import.repeated_event"
+	MsgImportExistingEvent Key = "import.existing_event"
+	MsgImportCheckFailed   Key = "import.event_check_failed"
+)
+
+// Recommendation reasons. Their parameters are documented next to each
+// reason type in the services package.
+const (
+	MsgReasonPositiveRating   Key = "reason.positive_rating"
+	MsgReasonTargetIncreased  Key = "reason.target_increased"
+	MsgReasonTargetAttractive Key = "reason.target_attractive"
+	MsgReasonBrokerUpgrade    Key = "reason.broker_upgrade"
+	MsgReasonNewCoverage      Key = "reason.new_coverage"
+	MsgReasonRecentEvent      Key = "reason.recent_event"
+)
+
+var catalog = map[Key]map[Lang]string{
+	MsgRouteNotFound: {
+		Spanish: "Ruta no encontrada",
+		English: "Route not found",
+	},
+	MsgMethodNotAllowed: {
+		Spanish: "Método no permitido para esta ruta",
+		English: "Method not allowed for this route",
+	},
+	MsgInvalidAdminCredentials: {
+		Spanish: "Credenciales de administrador inválidas",
+		English: "Invalid administrator credentials",
+	},
+	MsgInvalidLang: {
+		Spanish: "Parámetro lang inválido",
+		English: "Invalid lang parameter",
+	},
+	MsgInvalidView: {
+		Spanish: "Parámetro view inválido",
+		English: "Invalid view parameter",
+	},
+	MsgInvalidFields: {
+		Spanish: "Parámetro fields inválido",
+		English: "Invalid fields parameter",
+	},
+	MsgInvalidFormat: {
+		Spanish: "Parámetro format inválido",
+		English: "Invalid format parameter",
+	},
+	MsgInvalidSortBy: {
+		Spanish: "Parámetro sortBy inválido",
+		English: "Invalid sortBy parameter",
+	},
+	MsgInvalidSortOrder: {
+		Spanish: "Parámetro sortOrder inválido",
+		English: "Invalid sortOrder parameter",
+	},
+	MsgInvalidPage: {
+		Spanish: "Parámetro page inválido",
+		English: "Invalid page parameter",
+	},
+	MsgInvalidPageSize: {
+		Spanish: "Parámetro pageSize inválido",
+		English: "Invalid pageSize parameter",
+	},
+	MsgInvalidLimit: {
+		Spanish: "Parámetro limit inválido",
+		English: "Invalid limit parameter",
+	},
+	MsgInvalidID: {
+		Spanish: "Parámetro id inválido",
+		English: "Invalid id parameter",
+	},
+	MsgInvalidDryRun: {
+		Spanish: "Parámetro dry_run inválido",
+		English: "Invalid dry_run parameter",
+	},
+	MsgInvalidAuditFilters: {
+		Spanish: "Filtros de auditoría inválidos",
+		English: "Invalid audit filters",
+	},
+	MsgInvalidLastEventID: {
+		Spanish: "Parámetro Last-Event-ID inválido",
+		English: "Invalid Last-Event-ID parameter",
+	},
+	MsgTickerRequired: {
+		Spanish: "Parámetro ticker es requerido",
+		English: "The ticker parameter is required",
+	},
+	MsgMissingCSV: {
+		Spanish: "Falta el archivo CSV",
+		English: "The CSV file is missing",
+	},
+	MsgCSVTooLarge: {
+		Spanish: "El archivo CSV supera el tamaño máximo permitido",
+		English: "The CSV file exceeds the maximum allowed size",
+	},
+	MsgQueryTooLarge: {
+		Spanish: "La consulta GraphQL supera el tamaño máximo permitido",
+		English: "The GraphQL query exceeds the maximum allowed size",
+	},
+	MsgStockNotFound: {
+		Spanish: "Stock no encontrado",
+		English: "Stock not found",
+	},
+	MsgDeletedStockNotFound: {
+		Spanish: "Stock eliminado no encontrado",
+		English: "Deleted stock not found",
+	},
+	MsgListStocksFailed: {
+		Spanish: "Falló la obtención de acciones",
+		English: "Failed to get the stocks",
+	},
+	MsgGetStockFailed: {
+		Spanish: "Falló la obtención del stock",
+		English: "Failed to get the stock",
+	},
+	MsgRecommendationsFailed: {
+		Spanish: "Falló la obtención de recomendaciones",
+		English: "Failed to get the recommendations",
+	},
+	MsgListBrokeragesFailed: {
+		Spanish: "Falló la obtención de brokerages",
+		English: "Failed to get the brokerages",
+	},
+	MsgExportFailed: {
+		Spanish: "Falló la exportación de acciones",
+		English: "Failed to export the stocks",
+	},
+	MsgListDeletedStocksFailed: {
+		Spanish: "Falló la obtención de acciones eliminadas",
+		English: "Failed to get the deleted stocks",
+	},
+	MsgDeleteStockFailed: {
+		Spanish: "Falló la eliminación del stock",
+		English: "Failed to delete the stock",
+	},
+	MsgRestoreStockFailed: {
+		Spanish: "Falló la restauración del stock",
+		English: "Failed to restore the stock",
+	},
+	MsgPurgeStockFailed: {
+		Spanish: "Falló la eliminación definitiva del stock",
+		English: "Failed to permanently delete the stock",
+	},
+	MsgAuditFailed: {
+		Spanish: "Falló la obtención del registro de auditoría",
+		English: "Failed to get the audit log",
+	},
+	MsgImportFailed: {
+		Spanish: "Falló la importación de eventos",
+		English: "Failed to import the events",
+	},
+	MsgInvalidImportFile: {
+		Spanish: "Archivo CSV inválido: {reason}",
+		English: "Invalid CSV file: {reason}",
+	},
+	MsgStreamingNotSupported: {
+		Spanish: "El servidor no soporta streaming",
+		English: "The server does not support streaming",
+	},
+	MsgInternalError: {
+		Spanish: "Error interno del servidor",
+		English: "Internal server error",
+	},
+
+	MsgFieldRequired: {
+		Spanish: "es requerido",
+		English: "is required",
+	},
+	MsgFieldRequiredMultipart: {
+		Spanish: "es requerido en los formularios multipart",
+		English: "is required in multipart forms",
+	},
+	MsgFieldAllowedValues: {
+		Spanish: "valores permitidos: {values}",
+		English: "allowed values: {values}",
+	},
+	MsgFieldUnknownFields: {
+		Spanish: "campos desconocidos: {unknown}; campos permitidos: {allowed}",
+		English: "unknown fields: {unknown}; allowed fields: {allowed}",
+	},
+	MsgFieldPositiveInteger: {
+		Spanish: "debe ser un entero positivo",
+		English: "must be a positive integer",
+	},
+	MsgFieldNumeric: {
+		Spanish: "debe ser numérico",
+		English: "must be numeric",
+	},
+	MsgFieldRange: {
+		Spanish: "debe estar entre {min} y {max}",
+		English: "must be between {min} and {max}",
+	},
+	MsgFieldNonNegative: {
+		Spanish: "no puede ser negativo",
+		English: "cannot be negative",
+	},
+	MsgFieldEventID: {
+		Spanish: "debe ser un ID de evento numérico",
+		English: "must be a numeric event ID",
+	},
+	MsgFieldBoolean: {
+		Spanish: "debe ser true o false",
+		English: "must be true or false",
+	},
+	MsgFieldRFC3339: {
+		Spanish: "debe ser una fecha RFC 3339",
+		English: "must be an RFC 3339 date",
+	},
+
+	MsgImportEmptyFile: {
+		Spanish: "el archivo está vacío",
+		English: "the file is empty",
+	},
+	MsgImportMalformedCSV: {
+		Spanish: "CSV mal formado en la línea {line}",
+		English: "malformed CSV at line {line}",
+	},
+	MsgImportUnknownColumn: {
+		Spanish: "columna desconocida \"{column}\", columnas esperadas: {expected}",
+		English: "unknown column \"{column}\", expected columns: {expected}",
+	},
+	MsgImportMissingColumn: {
+		Spanish: "falta la columna \"{column}\"",
+		English: "the column \"{column}\" is missing",
+	},
+	MsgImportColumnCount: {
+		Spanish: "se esperaban {expected} columnas, se recibieron {received}",
+		English: "expected {expected} columns, got {received}",
+	},
+	MsgImportRequired: {
+		Spanish: "{column}: es requerido",
+		English: "{column}: is required",
+	},
+	MsgImportInvalidAmount: {
+		Spanish: "{column}: \"{value}\" no es un importe válido",
+		English: "{column}: \"{value}\" is not a valid amount",
+	},
+	MsgImportInvalidTime: {
+		Spanish: "{column}: \"{value}\" no es una fecha RFC 3339",
+		English: "{column}: \"{value}\" is not an RFC 3339 date",
+	},
+	MsgImportRepeatedEvent: {
+		Spanish: "evento repetido en el archivo",
+		English: "event repeated in the file",
+	},
+	MsgImportExistingEvent: {
+		Spanish: "evento ya registrado",
+		English: "event already stored",
+	},
+	MsgImportCheckFailed: {
+		Spanish: "error verificando el evento",
+		English: "failed to check the event",
+	},
+
+	MsgReasonPositiveRating: {
+		Spanish: "Rating positivo: {rating}",
+		English: "Positive rating: {rating}",
+	},
+	MsgReasonTargetIncreased: {
+		Spanish: "Precio objetivo aumentado de ${target_from} a ${target_to}",
+		English: "Price target raised from ${target_from} to ${target_to}",
+	},
+	MsgReasonTargetAttractive: {
+		Spanish: "Precio objetivo atractivo: ${target_to}",
+		English: "Attractive price target: ${target_to}",
+	},
+	MsgReasonBrokerUpgrade: {
+		Spanish: "Mejorada por {brokerage}",
+		English: "Upgraded by {brokerage}",
+	},
+	MsgReasonNewCoverage: {
+		Spanish: "Nueva cobertura ({brokerage}) iniciada con rating positivo: {rating}",
+		English: "New coverage ({brokerage}) initiated with a positive rating: {rating}",
+	},
+	MsgReasonRecentEvent: {
+		Spanish: "Evento de rating reciente (últimos {months} meses).",
+		English: "Recent rating event (last {months} months).",
+	},
+}
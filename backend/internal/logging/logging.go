@@ -0,0 +1,78 @@
+// Package logging sets up the structured JSON logger of the commands and
+// lets a context carry attributes, such as the request or sync run ID, that
+// are added to every line logged with it.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+var level slog.LevelVar
+
+// Setup makes a JSON logger writing to w the default of log/slog, and so of
+// the standard log package too. It logs at info level until SetLevel is
+// called, so that lines logged while the configuration loads are kept.
+func Setup(w io.Writer) {
+	slog.SetDefault(slog.New(NewHandler(w, &level)))
+}
+
+// SetLevel changes the minimum level of the logger installed by Setup.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// NewHandler returns a JSON handler writing to w the records of at least
+// leveler's level, along with the attributes of their context.
+func NewHandler(w io.Writer, leveler slog.Leveler) slog.Handler {
+	return contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: leveler})}
+}
+
+// Fatal logs msg at error level and exits with status 1.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+type contextKey struct{}
+
+// With returns a copy of ctx whose log lines carry attrs, after those
+// already carried by ctx.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	parent := attrsFromContext(ctx)
+	combined := make([]slog.Attr, 0, len(parent)+len(attrs))
+	combined = append(combined, parent...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, contextKey{}, combined)
+}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler adds the attributes stored by With to each record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := attrsFromContext(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
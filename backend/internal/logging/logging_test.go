@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(raw), &line), "line %q is not JSON", raw)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestHandler_AddsContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, slog.LevelInfo))
+
+	ctx := With(context.Background(), slog.String("request_id", "req-1"))
+	ctx = With(ctx, slog.String("run_id", "run-1"))
+
+	logger.InfoContext(ctx, "con contexto", "page", 2)
+	logger.With("component", "sync").WarnContext(ctx, "con atributos del logger")
+	logger.Info("sin contexto")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 3)
+
+	assert.Equal(t, "con contexto", lines[0]["msg"])
+	assert.Equal(t, "INFO", lines[0]["level"])
+	assert.Equal(t, "req-1", lines[0]["request_id"])
+	assert.Equal(t, "run-1", lines[0]["run_id"])
+	assert.Equal(t, float64(2), lines[0]["page"])
+
+	assert.Equal(t, "sync", lines[1]["component"])
+	assert.Equal(t, "req-1", lines[1]["request_id"], "loggers derived with With keep the context attributes")
+
+	assert.NotContains(t, lines[2], "request_id")
+}
+
+func TestHandler_Level(t *testing.T) {
+	var buf bytes.Buffer
+	var level slog.LevelVar
+	logger := slog.New(NewHandler(&buf, &level))
+
+	logger.Debug("oculto")
+	level.Set(slog.LevelDebug)
+	logger.Debug("visible")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "visible", lines[0]["msg"])
+}
+
+func TestWith_DoesNotAffectParent(t *testing.T) {
+	parent := With(context.Background(), slog.String("request_id", "req-1"))
+	_ = With(parent, slog.String("run_id", "run-1"))
+
+	assert.Equal(t, []slog.Attr{slog.String("request_id", "req-1")}, attrsFromContext(parent))
+}
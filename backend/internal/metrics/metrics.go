@@ -0,0 +1,191 @@
+// Package metrics holds the Prometheus metrics of the service: HTTP traffic,
+// the database pool, the external API sync and the recommendation engine.
+// The sync metrics are read from the recorded sync runs on every scrape, so
+// they cover runs made by any process.
+//
+// Every method of *Metrics is safe to call on a nil receiver, which records
+// nothing, so that commands and tests may run without metrics.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"stockify/internal/core"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "stockify"
+
+// Results of a synced item, used as the result label of the items counter.
+const (
+	SyncItemCreated = "created"
+	SyncItemSkipped = "skipped"
+	SyncItemFailed  = "failed"
+)
+
+// Metrics is a registry with the collectors of the service.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	recommendationDuration prometheus.Histogram
+}
+
+// New creates the metrics, along with the Go runtime and process collectors,
+// in a registry of their own.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests served, by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+
+		recommendationDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "recommendations",
+			Name:      "duration_seconds",
+			Help:      "Time taken to compute the stock recommendations.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.recommendationDuration,
+	)
+
+	return m
+}
+
+// RegisterDB exports the connection pool statistics of db, labelled with
+// name, as the go_sql_* metrics.
+func (m *Metrics) RegisterDB(name string, db *sql.DB) error {
+	if m == nil {
+		return nil
+	}
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// SyncStatsSource adds up the recorded sync runs.
+type SyncStatsSource interface {
+	SyncStats(ctx context.Context) (core.SyncStats, error)
+}
+
+// RegisterSync exports the totals of the sync runs recorded in source, read
+// at scrape time.
+func (m *Metrics) RegisterSync(source SyncStatsSource) error {
+	if m == nil {
+		return nil
+	}
+	return m.registry.Register(newSyncCollector(source))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTPRequest records a request served by the handler of route, the
+// pattern it matched, such as /api/v1/stocks/{ticker}.
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveRecommendations records the time taken to compute the
+// recommendations.
+func (m *Metrics) ObserveRecommendations(duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.recommendationDuration.Observe(duration.Seconds())
+}
+
+var (
+	syncPagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "sync", "pages_total"),
+		"Pages downloaded from the external API by the recorded sync runs.",
+		nil, nil)
+	syncItemsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "sync", "items_total"),
+		"Items received from the external API by the recorded sync runs, by result: created, skipped as already stored, or failed to store.",
+		[]string{"result"}, nil)
+	syncFailuresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "sync", "failures_total"),
+		"Sync runs that ended with an error.",
+		nil, nil)
+	syncLastSuccessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "sync", "last_success_timestamp_seconds"),
+		"Unix time at which the last successful sync run finished, 0 if none did.",
+		nil, nil)
+)
+
+// syncCollectTimeout bounds the query made for each scrape.
+const syncCollectTimeout = 5 * time.Second
+
+// syncCollector reads the sync metrics from the recorded runs on each scrape.
+type syncCollector struct {
+	source SyncStatsSource
+}
+
+func newSyncCollector(source SyncStatsSource) *syncCollector {
+	return &syncCollector{source: source}
+}
+
+func (c *syncCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- syncPagesDesc
+	ch <- syncItemsDesc
+	ch <- syncFailuresDesc
+	ch <- syncLastSuccessDesc
+}
+
+// Collect sends nothing if the runs cannot be read, so that the rest of the
+// metrics are still served while the database is down.
+func (c *syncCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncCollectTimeout)
+	defer cancel()
+
+	stats, err := c.source.SyncStats(ctx)
+	if err != nil {
+		slog.Warn("Sync metrics unavailable", "error", err)
+		return
+	}
+
+	lastSuccess := 0.0
+	if stats.LastSuccess != nil {
+		lastSuccess = float64(stats.LastSuccess.FinishedAt.UnixNano()) / float64(time.Second)
+	}
+
+	ch <- prometheus.MustNewConstMetric(syncPagesDesc, prometheus.CounterValue, float64(stats.Pages))
+	ch <- prometheus.MustNewConstMetric(syncItemsDesc, prometheus.CounterValue, float64(stats.Created), SyncItemCreated)
+	ch <- prometheus.MustNewConstMetric(syncItemsDesc, prometheus.CounterValue, float64(stats.Skipped), SyncItemSkipped)
+	ch <- prometheus.MustNewConstMetric(syncItemsDesc, prometheus.CounterValue, float64(stats.Failed), SyncItemFailed)
+	ch <- prometheus.MustNewConstMetric(syncFailuresDesc, prometheus.CounterValue, float64(stats.FailedRuns))
+	ch <- prometheus.MustNewConstMetric(syncLastSuccessDesc, prometheus.GaugeValue, lastSuccess)
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"stockify/internal/core"
+	"testing"
+	"time"
+
+	_ "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// syncStats is a SyncStatsSource with fixed totals.
+type syncStats core.SyncStats
+
+func (s syncStats) SyncStats(ctx context.Context) (core.SyncStats, error) {
+	return core.SyncStats(s), nil
+}
+
+type failingSyncStats struct{}
+
+func (failingSyncStats) SyncStats(ctx context.Context) (core.SyncStats, error) {
+	return core.SyncStats{}, errors.New("database is down")
+}
+
+func TestMetrics_Exposition(t *testing.T) {
+	m := New()
+
+	m.ObserveHTTPRequest(http.MethodGet, "/api/v1/stocks/{ticker}", http.StatusOK, 20*time.Millisecond)
+	require.NoError(t, m.RegisterSync(syncStats{Pages: 2, Created: 4, Skipped: 3, Failed: 1, FailedRuns: 1, LastSuccess: &core.SyncRun{FinishedAt: time.Unix(1700000000, 0)}}))
+	m.ObserveRecommendations(150 * time.Millisecond)
+
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, m.RegisterDB("stockify", db))
+
+	body := scrape(t, m)
+	for _, line := range []string{
+		`stockify_http_requests_total{method="GET",route="/api/v1/stocks/{ticker}",status="200"} 1`,
+		`stockify_http_request_duration_seconds_count{method="GET",route="/api/v1/stocks/{ticker}"} 1`,
+		`stockify_sync_pages_total 2`,
+		`stockify_sync_items_total{result="created"} 4`,
+		`stockify_sync_items_total{result="skipped"} 3`,
+		`stockify_sync_items_total{result="failed"} 1`,
+		`stockify_sync_failures_total 1`,
+		`stockify_sync_last_success_timestamp_seconds 1.7e+09`,
+		`stockify_recommendations_duration_seconds_count 1`,
+		`go_sql_max_open_connections{db_name="stockify"} 0`,
+		`go_goroutines `,
+	} {
+		assert.Contains(t, body, line)
+	}
+}
+
+func TestMetrics_NilRecordsNothing(t *testing.T) {
+	var m *Metrics
+
+	assert.NotPanics(t, func() {
+		m.ObserveHTTPRequest(http.MethodGet, "/", http.StatusOK, time.Millisecond)
+		m.ObserveRecommendations(time.Millisecond)
+		assert.NoError(t, m.RegisterDB("stockify", nil))
+		assert.NoError(t, m.RegisterSync(syncStats{}))
+	})
+}
+
+func TestMetrics_SyncWithoutRuns(t *testing.T) {
+	m := New()
+	require.NoError(t, m.RegisterSync(syncStats{}))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `stockify_sync_failures_total 0`)
+	assert.Contains(t, body, `stockify_sync_last_success_timestamp_seconds 0`)
+}
+
+func TestMetrics_SyncStatsUnavailable(t *testing.T) {
+	m := New()
+	require.NoError(t, m.RegisterSync(failingSyncStats{}))
+
+	body := scrape(t, m)
+	assert.NotContains(t, body, "stockify_sync_")
+	assert.Contains(t, body, "go_goroutines ")
+}
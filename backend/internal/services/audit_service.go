@@ -0,0 +1,19 @@
+package services
+
+import (
+	"context"
+	"stockify/internal/core"
+	"stockify/internal/store"
+)
+
+type AuditService struct {
+	store store.AuditStoreInterface
+}
+
+func NewAuditService(s store.AuditStoreInterface) *AuditService {
+	return &AuditService{store: s}
+}
+
+func (svc *AuditService) ListEntries(ctx context.Context, params store.AuditQueryParams) ([]core.AuditEntry, int64, error) {
+	return svc.store.ListAuditEntries(ctx, params)
+}
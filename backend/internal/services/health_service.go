@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"stockify/internal/core"
+	"stockify/internal/store"
+	"time"
+)
+
+type HealthService struct {
+	counter    store.StockCounterInterface
+	syncStore  store.SyncStoreInterface
+	pinger     store.PingerInterface
+	staleAfter time.Duration
+}
+
+// NewHealthService creates the health checks. The data is considered stale
+// when the last successful sync finished more than staleAfter ago; zero
+// disables the threshold. counter should not be cached, so that the row count
+// is current.
+func NewHealthService(counter store.StockCounterInterface, runs store.SyncStoreInterface, pinger store.PingerInterface, staleAfter time.Duration) *HealthService {
+	return &HealthService{counter: counter, syncStore: runs, pinger: pinger, staleAfter: staleAfter}
+}
+
+// DataHealth describes how fresh the rating events are.
+type DataHealth struct {
+	// LastSync is the last successful sync run, nil if there was none.
+	LastSync   *core.SyncRun
+	RowCount   int64
+	StaleAfter time.Duration
+	// Stale is set when the sync never succeeded or its last success is
+	// older than StaleAfter.
+	Stale bool
+}
+
+// Ping checks that the database answers.
+func (svc *HealthService) Ping(ctx context.Context) error {
+	return svc.pinger.Ping(ctx)
+}
+
+func (svc *HealthService) DataHealth(ctx context.Context) (DataHealth, error) {
+	lastSync, err := svc.syncStore.LastSyncRun(ctx)
+	if err != nil {
+		return DataHealth{}, err
+	}
+
+	count, err := svc.counter.CountStocks(ctx)
+	if err != nil {
+		return DataHealth{}, err
+	}
+
+	health := DataHealth{LastSync: lastSync, RowCount: count, StaleAfter: svc.staleAfter}
+	switch {
+	case lastSync == nil:
+		health.Stale = true
+	case svc.staleAfter > 0:
+		health.Stale = time.Since(lastSync.FinishedAt) > svc.staleAfter
+	}
+
+	return health, nil
+}
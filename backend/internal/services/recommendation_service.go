@@ -1,10 +1,13 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sort"
 	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/metrics"
 	"stockify/internal/store"
 	"strings"
 	"time"
@@ -12,26 +15,68 @@ import (
 
 type RecommendationService struct {
 	stockStore store.StockStoreInterface
+	metrics    *metrics.Metrics
 }
 
 func NewRecommendationService(ss store.StockStoreInterface) *RecommendationService {
 	return &RecommendationService{stockStore: ss}
 }
 
+// SetMetrics makes the service record how long computing the recommendations
+// takes in m.
+func (svc *RecommendationService) SetMetrics(m *metrics.Metrics) {
+	svc.metrics = m
+}
+
 type RecommendationReasonType string
 
+// The parameters of each reason type are listed next to it.
 const (
-	ReasonTypePositiveRating   RecommendationReasonType = "POSITIVE_RATING"
-	ReasonTypeTargetIncreased  RecommendationReasonType = "TARGET_INCREASED"
-	ReasonTypeTargetAttractive RecommendationReasonType = "TARGET_ATTRACTIVE"
-	ReasonTypeBrokerUpgrade    RecommendationReasonType = "BROKER_UPGRADE"
-	ReasonTypeNewCoverage      RecommendationReasonType = "NEW_POSITIVE_COVERAGE"
-	ReasonTypeRecentEvent      RecommendationReasonType = "RECENT_EVENT"
+	ReasonTypePositiveRating   RecommendationReasonType = "POSITIVE_RATING"       // rating
+	ReasonTypeTargetIncreased  RecommendationReasonType = "TARGET_INCREASED"      // target_from, target_to
+	ReasonTypeTargetAttractive RecommendationReasonType = "TARGET_ATTRACTIVE"     // target_to
+	ReasonTypeBrokerUpgrade    RecommendationReasonType = "BROKER_UPGRADE"        // brokerage
+	ReasonTypeNewCoverage      RecommendationReasonType = "NEW_POSITIVE_COVERAGE" // brokerage, rating
+	ReasonTypeRecentEvent      RecommendationReasonType = "RECENT_EVENT"          // months
 )
 
+var reasonMessages = map[RecommendationReasonType]i18n.Key{
+	ReasonTypePositiveRating:   i18n.MsgReasonPositiveRating,
+	ReasonTypeTargetIncreased:  i18n.MsgReasonTargetIncreased,
+	ReasonTypeTargetAttractive: i18n.MsgReasonTargetAttractive,
+	ReasonTypeBrokerUpgrade:    i18n.MsgReasonBrokerUpgrade,
+	ReasonTypeNewCoverage:      i18n.MsgReasonNewCoverage,
+	ReasonTypeRecentEvent:      i18n.MsgReasonRecentEvent,
+}
+
+// RecommendationReason explains part of a recommendation's score. Params
+// hold the values the reason refers to, so that clients may word it
+// themselves; Details is the reason worded in the default language.
 type RecommendationReason struct {
 	Type    RecommendationReasonType `json:"type"`
 	Details string                   `json:"details"`
+	Params  i18n.Params              `json:"params"`
+}
+
+func newReason(reasonType RecommendationReasonType, params i18n.Params) RecommendationReason {
+	reason := RecommendationReason{Type: reasonType, Params: params}
+	reason.Details = reason.Localize(i18n.Default)
+	return reason
+}
+
+// Localize words the reason in lang.
+func (reason RecommendationReason) Localize(lang i18n.Lang) string {
+	return i18n.T(lang, reasonMessages[reason.Type], reason.Params)
+}
+
+// StringParams returns Params with every value as text, for the APIs whose
+// schema only carries strings.
+func (reason RecommendationReason) StringParams() map[string]string {
+	params := make(map[string]string, len(reason.Params))
+	for name, value := range reason.Params {
+		params[name] = fmt.Sprint(value)
+	}
+	return params
 }
 
 type RecommendedStock struct {
@@ -40,10 +85,11 @@ type RecommendedStock struct {
 	Score   float64                `json:"score"`
 }
 
-func (svc *RecommendationService) GetRecommendations() ([]RecommendedStock, error) {
-	log.Println("RecommendationService: Iniciando obtención de recomendaciones...")
+func (svc *RecommendationService) GetRecommendations(ctx context.Context) ([]RecommendedStock, error) {
+	slog.DebugContext(ctx, "RecommendationService: Iniciando obtención de recomendaciones")
+	start := time.Now()
 
-	allStocks, _, err := svc.stockStore.GetStocks(store.GetStocksParams{
+	allStocks, _, err := svc.stockStore.GetStocks(ctx, store.GetStocksParams{
 		SortBy:    "time",
 		SortOrder: "desc",
 		PageSize:  500,
@@ -51,11 +97,11 @@ func (svc *RecommendationService) GetRecommendations() ([]RecommendedStock, erro
 	})
 
 	if err != nil {
-		log.Printf("RecommendationService: Error obteniendo stocks para recomendaciones: %v", err)
+		slog.ErrorContext(ctx, "RecommendationService: Error obteniendo stocks para recomendaciones", "error", err)
 		return nil, err
 	}
 
-	log.Printf("RecommendationService: Obtenidos %d stocks para analizar.", len(allStocks))
+	slog.DebugContext(ctx, "RecommendationService: Stocks obtenidos para analizar", "stocks", len(allStocks))
 
 	var candidates []RecommendedStock
 
@@ -69,7 +115,8 @@ func (svc *RecommendationService) GetRecommendations() ([]RecommendedStock, erro
 		"positive":   true,
 	}
 
-	maxAgeForHighConsideration := time.Now().AddDate(0, -3, 0)
+	const recentMonths = 3
+	maxAgeForHighConsideration := time.Now().AddDate(0, -recentMonths, 0)
 
 	for _, stock := range allStocks {
 		var score float64 = 0
@@ -77,10 +124,7 @@ func (svc *RecommendationService) GetRecommendations() ([]RecommendedStock, erro
 
 		if positiveRatings[strings.ToLower(stock.RatingTo)] {
 			score += 50
-			reasons = append(reasons, RecommendationReason{
-				Type:    ReasonTypePositiveRating,
-				Details: fmt.Sprintf("Rating positivo: %s", stock.RatingTo),
-			})
+			reasons = append(reasons, newReason(ReasonTypePositiveRating, i18n.Params{"rating": stock.RatingTo}))
 		} else {
 			continue
 		}
@@ -89,40 +133,25 @@ func (svc *RecommendationService) GetRecommendations() ([]RecommendedStock, erro
 			score += (*stock.TargetTo / 10)
 			if stock.TargetFrom != nil && *stock.TargetTo > *stock.TargetFrom {
 				score += 20
-				reasons = append(reasons, RecommendationReason{
-					Type:    ReasonTypeTargetIncreased,
-					Details: fmt.Sprintf("Precio objetivo aumentado de $%.2f a $%.2f", *stock.TargetFrom, *stock.TargetTo),
-				})
+				reasons = append(reasons, newReason(ReasonTypeTargetIncreased, i18n.Params{"target_from": *stock.TargetFrom, "target_to": *stock.TargetTo}))
 			} else {
-				reasons = append(reasons, RecommendationReason{
-					Type:    ReasonTypeTargetAttractive,
-					Details: fmt.Sprintf("Precio objetivo atractivo: $%.2f", *stock.TargetTo),
-				})
+				reasons = append(reasons, newReason(ReasonTypeTargetAttractive, i18n.Params{"target_to": *stock.TargetTo}))
 			}
 		}
 
 		if strings.Contains(strings.ToLower(stock.Action), "upgraded by") {
 			score += 30
-			reasons = append(reasons, RecommendationReason{
-				Type:    ReasonTypeBrokerUpgrade,
-				Details: fmt.Sprintf("Mejorada por %s", stock.Brokerage),
-			})
+			reasons = append(reasons, newReason(ReasonTypeBrokerUpgrade, i18n.Params{"brokerage": stock.Brokerage}))
 		}
 
 		if strings.Contains(strings.ToLower(stock.Action), "initiated by") && positiveRatings[strings.ToLower(stock.RatingTo)] {
 			score += 25
-			reasons = append(reasons, RecommendationReason{
-				Type:    ReasonTypeNewCoverage,
-				Details: fmt.Sprintf("Nueva cobertura (%s) iniciada con rating positivo: %s", stock.Brokerage, stock.RatingTo),
-			})
+			reasons = append(reasons, newReason(ReasonTypeNewCoverage, i18n.Params{"brokerage": stock.Brokerage, "rating": stock.RatingTo}))
 		}
 
 		if stock.Time.After(maxAgeForHighConsideration) {
 			score += 15
-			reasons = append(reasons, RecommendationReason{
-				Type:    ReasonTypeRecentEvent,
-				Details: "Evento de rating reciente (últimos 3 meses).",
-			})
+			reasons = append(reasons, newReason(ReasonTypeRecentEvent, i18n.Params{"months": recentMonths}))
 		}
 
 		if score > 50 {
@@ -134,7 +163,7 @@ func (svc *RecommendationService) GetRecommendations() ([]RecommendedStock, erro
 		}
 	}
 
-	log.Printf("RecommendationService: Encontrados %d candidatos después del scoring.", len(candidates))
+	slog.DebugContext(ctx, "RecommendationService: Candidatos encontrados después del scoring", "candidates", len(candidates))
 
 	sort.Slice(candidates, func(i, j int) bool {
 		return candidates[i].Score > candidates[j].Score
@@ -146,7 +175,9 @@ func (svc *RecommendationService) GetRecommendations() ([]RecommendedStock, erro
 	}
 
 	finalRecommendations := candidates[:numRecommendations]
-	log.Printf("RecommendationService: Devolviendo %d recomendaciones.", len(finalRecommendations))
+	duration := time.Since(start)
+	svc.metrics.ObserveRecommendations(duration)
+	slog.InfoContext(ctx, "RecommendationService: Recomendaciones calculadas", "recommendations", len(finalRecommendations), "duration_ms", duration.Milliseconds())
 
 	return finalRecommendations, nil
 }
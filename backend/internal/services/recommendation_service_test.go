@@ -1,8 +1,10 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"stockify/internal/core"
+	"stockify/internal/i18n"
 	"stockify/internal/services"
 	"stockify/internal/store"
 	"testing"
@@ -31,8 +33,8 @@ func TestRecommendationService_GetRecommendations(t *testing.T) {
 	}
 
 	expectedParams := store.GetStocksParams{SortBy: "time", SortOrder: "desc", PageSize: 500, Page: 1}
-	mockStore.On("GetStocks", expectedParams).Return(testStocks, int64(len(testStocks)), nil).Once()
-	recommendations, err := recommendationService.GetRecommendations()
+	mockStore.On("GetStocks", mock.Anything, expectedParams).Return(testStocks, int64(len(testStocks)), nil).Once()
+	recommendations, err := recommendationService.GetRecommendations(context.Background())
 
 	assert.NoError(t, err)
 	assert.NotNil(t, recommendations)
@@ -49,6 +51,8 @@ func TestRecommendationService_GetRecommendations(t *testing.T) {
 			if reason.Type == services.ReasonTypeBrokerUpgrade {
 				foundUpgradeReason = true
 				assert.Contains(t, reason.Details, "Broker A")
+				assert.Equal(t, i18n.Params{"brokerage": "Broker A"}, reason.Params)
+				assert.Equal(t, "Upgraded by Broker A", reason.Localize(i18n.English))
 			}
 		}
 		assert.True(t, foundUpgradeReason, "Debería tener una razón de tipo BrokerUpgrade")
@@ -76,11 +80,29 @@ func TestRecommendationService_GetRecommendations_StoreError(t *testing.T) {
 	mockStore := new(MockStockStore)
 	recommendationService := services.NewRecommendationService(mockStore)
 	expectedError := errors.New("store failed")
-	mockStore.On("GetStocks", mock.AnythingOfType("store.GetStocksParams")).Return(nil, int64(0), expectedError)
-	recommendations, err := recommendationService.GetRecommendations()
+	mockStore.On("GetStocks", mock.Anything, mock.AnythingOfType("store.GetStocksParams")).Return(nil, int64(0), expectedError)
+	recommendations, err := recommendationService.GetRecommendations(context.Background())
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
 	assert.Nil(t, recommendations)
 	mockStore.AssertExpectations(t)
 }
+
+func TestRecommendationService_GetRecommendations_MemoryStore(t *testing.T) {
+	now := time.Now()
+	memoryStore := store.NewMemoryStore([]core.Stock{
+		{Ticker: "GOOD1", RatingTo: "Buy", TargetTo: float64Ptr(100.0), Time: now, Action: "upgraded by", Brokerage: "Broker A"},
+		{Ticker: "HOLD1", RatingTo: "Hold", TargetTo: float64Ptr(500.0), Time: now},
+		{Ticker: "GOOD2", RatingTo: "Outperform", TargetTo: float64Ptr(40.0), Time: now.AddDate(0, -6, 0)},
+	})
+	recommendationService := services.NewRecommendationService(memoryStore)
+
+	recommendations, err := recommendationService.GetRecommendations(context.Background())
+
+	assert.NoError(t, err)
+	if assert.Len(t, recommendations, 2) {
+		assert.Equal(t, "GOOD1", recommendations[0].Ticker)
+		assert.Equal(t, "GOOD2", recommendations[1].Ticker)
+	}
+}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"stockify/internal/core"
 	"stockify/internal/store"
+	"time"
 )
 
 type StockService struct {
@@ -13,10 +15,59 @@ func NewStockService(s store.StockStoreInterface) *StockService {
 	return &StockService{store: s}
 }
 
-func (svc *StockService) ListStocks(params store.GetStocksParams) ([]core.Stock, int64, error) {
-	return svc.store.GetStocks(params)
+func (svc *StockService) ListStocks(ctx context.Context, params store.GetStocksParams) ([]core.Stock, int64, error) {
+	return svc.store.GetStocks(ctx, params)
 }
 
-func (svc *StockService) GetStockByTicker(ticker string) (*core.Stock, error) {
-	return svc.store.GetStockByTicker(ticker)
+// ExportStocks calls fn for every event matching the search and sorting of
+// params, without pagination.
+func (svc *StockService) ExportStocks(ctx context.Context, params store.GetStocksParams, fn func(core.Stock) error) error {
+	return svc.store.StreamStocks(ctx, params, fn)
+}
+
+func (svc *StockService) ListLatestStocks(ctx context.Context, params store.GetStocksParams) ([]core.LatestStock, int64, error) {
+	return svc.store.GetLatestStocks(ctx, params)
+}
+
+func (svc *StockService) GetStockByTicker(ctx context.Context, ticker string, includeArchived bool) (*core.Stock, error) {
+	return svc.store.GetStockByTicker(ctx, ticker, includeArchived)
+}
+
+// ListEventsByTickers returns every event of tickers, newest first, with a
+// single store query however many tickers are requested. Archived events are
+// included if includeArchived is set.
+func (svc *StockService) ListEventsByTickers(ctx context.Context, tickers []string, includeArchived bool) ([]core.Stock, error) {
+	return svc.store.GetStocksByTickers(ctx, tickers, includeArchived)
+}
+
+// ListStocksAfter returns up to limit events created after the event afterID,
+// oldest first.
+func (svc *StockService) ListStocksAfter(ctx context.Context, afterID uint, limit int) ([]core.Stock, error) {
+	return svc.store.GetStocksAfterID(ctx, afterID, limit)
+}
+
+func (svc *StockService) ListBrokerages(ctx context.Context, search string) ([]store.BrokerageSummary, error) {
+	return svc.store.GetBrokerages(ctx, search)
+}
+
+// LastModified returns when the rating events last changed, to validate
+// cached responses.
+func (svc *StockService) LastModified(ctx context.Context) (time.Time, error) {
+	return svc.store.LastModified(ctx)
+}
+
+func (svc *StockService) ListDeletedStocks(ctx context.Context, params store.GetStocksParams) ([]core.Stock, int64, error) {
+	return svc.store.GetDeletedStocks(ctx, params)
+}
+
+func (svc *StockService) DeleteStock(ctx context.Context, id uint) (*core.Stock, error) {
+	return svc.store.SoftDeleteStock(ctx, id)
+}
+
+func (svc *StockService) RestoreStock(ctx context.Context, id uint) (*core.Stock, error) {
+	return svc.store.RestoreStock(ctx, id)
+}
+
+func (svc *StockService) PurgeStock(ctx context.Context, id uint) (bool, error) {
+	return svc.store.PurgeStock(ctx, id)
 }
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"stockify/internal/core"
 	"stockify/internal/services"
@@ -16,8 +17,8 @@ type MockStockStore struct {
 	store.StockStoreInterface
 }
 
-func (m *MockStockStore) GetStocks(params store.GetStocksParams) ([]core.Stock, int64, error) {
-	args := m.Called(params)
+func (m *MockStockStore) GetStocks(ctx context.Context, params store.GetStocksParams) ([]core.Stock, int64, error) {
+	args := m.Called(ctx, params)
 
 	var stocks []core.Stock
 
@@ -28,8 +29,20 @@ func (m *MockStockStore) GetStocks(params store.GetStocksParams) ([]core.Stock,
 	return stocks, args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockStockStore) GetStockByTicker(ticker string) (*core.Stock, error) {
-	args := m.Called(ticker)
+func (m *MockStockStore) GetLatestStocks(ctx context.Context, params store.GetStocksParams) ([]core.LatestStock, int64, error) {
+	args := m.Called(ctx, params)
+
+	var stocks []core.LatestStock
+
+	if arg0 := args.Get(0); arg0 != nil {
+		stocks = arg0.([]core.LatestStock)
+	}
+
+	return stocks, args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStockStore) GetStockByTicker(ctx context.Context, ticker string, includeArchived bool) (*core.Stock, error) {
+	args := m.Called(ctx, ticker, includeArchived)
 
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -38,13 +51,13 @@ func (m *MockStockStore) GetStockByTicker(ticker string) (*core.Stock, error) {
 	return args.Get(0).(*core.Stock), args.Error(1)
 }
 
-func (m *MockStockStore) CountStocks() (int64, error) {
-	args := m.Called()
+func (m *MockStockStore) CountStocks(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockStockStore) GetRawStocksForRecommendation(limit int) ([]core.Stock, error) {
-	args := m.Called(limit)
+func (m *MockStockStore) GetRawStocksForRecommendation(ctx context.Context, limit int) ([]core.Stock, error) {
+	args := m.Called(ctx, limit)
 
 	var stocks []core.Stock
 
@@ -62,9 +75,41 @@ func TestStockService_ListStocks(t *testing.T) {
 	expectedTotal := int64(1)
 	params := store.GetStocksParams{Page: 1, PageSize: 10}
 
-	mockStore.On("GetStocks", params).Return(expectedStocks, expectedTotal, nil)
+	mockStore.On("GetStocks", mock.Anything, params).Return(expectedStocks, expectedTotal, nil)
+
+	stocks, total, err := stockService.ListStocks(context.Background(), params)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedStocks, stocks)
+	assert.Equal(t, expectedTotal, total)
+	mockStore.AssertExpectations(t)
+}
+
+func TestStockService_ListStocks_PropagatesContext(t *testing.T) {
+	mockStore := new(MockStockStore)
+	stockService := services.NewStockService(mockStore)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	params := store.GetStocksParams{Page: 1, PageSize: 10}
+
+	mockStore.On("GetStocks", ctx, params).Return(nil, int64(0), nil)
+
+	_, _, err := stockService.ListStocks(ctx, params)
+
+	assert.NoError(t, err)
+	mockStore.AssertExpectations(t)
+}
+
+func TestStockService_ListLatestStocks(t *testing.T) {
+	mockStore := new(MockStockStore)
+	stockService := services.NewStockService(mockStore)
+	expectedStocks := []core.LatestStock{{Stock: core.Stock{Ticker: "AAPL"}, EventCount: 3}}
+	expectedTotal := int64(1)
+	params := store.GetStocksParams{Search: "aa", SortBy: "ticker", Page: 1, PageSize: 10}
+
+	mockStore.On("GetLatestStocks", mock.Anything, params).Return(expectedStocks, expectedTotal, nil)
 
-	stocks, total, err := stockService.ListStocks(params)
+	stocks, total, err := stockService.ListLatestStocks(context.Background(), params)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedStocks, stocks)
@@ -78,9 +123,9 @@ func TestStockService_GetStockByTicker_Found(t *testing.T) {
 	expectedStock := &core.Stock{Ticker: "AAPL", Company: "Apple Inc."}
 	ticker := "AAPL"
 
-	mockStore.On("GetStockByTicker", ticker).Return(expectedStock, nil)
+	mockStore.On("GetStockByTicker", mock.Anything, ticker, false).Return(expectedStock, nil)
 
-	stock, err := stockService.GetStockByTicker(ticker)
+	stock, err := stockService.GetStockByTicker(context.Background(), ticker, false)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedStock, stock)
@@ -92,9 +137,9 @@ func TestStockService_GetStockByTicker_NotFound(t *testing.T) {
 	stockService := services.NewStockService(mockStore)
 	ticker := "UNKNOWN"
 
-	mockStore.On("GetStockByTicker", ticker).Return(nil, nil)
+	mockStore.On("GetStockByTicker", mock.Anything, ticker, false).Return(nil, nil)
 
-	stock, err := stockService.GetStockByTicker(ticker)
+	stock, err := stockService.GetStockByTicker(context.Background(), ticker, false)
 
 	assert.NoError(t, err)
 	assert.Nil(t, stock)
@@ -107,9 +152,9 @@ func TestStockService_GetStockByTicker_StoreError(t *testing.T) {
 	ticker := "ERROR"
 	expectedError := errors.New("database error")
 
-	mockStore.On("GetStockByTicker", ticker).Return(nil, expectedError)
+	mockStore.On("GetStockByTicker", mock.Anything, ticker, false).Return(nil, expectedError)
 
-	stock, err := stockService.GetStockByTicker(ticker)
+	stock, err := stockService.GetStockByTicker(context.Background(), ticker, false)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
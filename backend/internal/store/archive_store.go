@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"stockify/internal/core"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// archiveBatchSize bounds how many events each archival transaction moves, so
+// that archiving a large backlog does not hold one huge transaction open.
+const archiveBatchSize = 500
+
+// stockColumns lists the columns shared by the stocks and stocks_archive
+// tables.
+const stockColumns = "id, created_at, updated_at, deleted_at, ticker, company, brokerage, action, rating_to, rating_from, target_to, target_from, time"
+
+// stockRows returns a query over the rating events, reading the archive table
+// as well as the live one when includeArchived is set. Either way the rows
+// are exposed as the stocks table so that callers can filter and sort them
+// alike.
+func stockRows(db *gorm.DB, includeArchived bool) *gorm.DB {
+	if !includeArchived {
+		return db.Model(&core.Stock{})
+	}
+
+	union := db.Raw("SELECT " + stockColumns + " FROM stocks UNION ALL SELECT " + stockColumns + " FROM stocks_archive")
+	return db.Model(&core.Stock{}).Table("(?) AS stocks", union)
+}
+
+// ArchiveStocks moves every event whose time is before cutoff, including
+// soft-deleted ones, from the stocks table into stocks_archive, recording an
+// archive audit entry for each. It returns the number of events moved, which
+// is accurate even when a later batch fails.
+func (s *StockStore) ArchiveStocks(ctx context.Context, cutoff time.Time) (int64, error) {
+	var archived int64
+
+	for {
+		moved, err := s.archiveBatch(ctx, cutoff.UTC())
+		archived += moved
+
+		if err != nil || moved < archiveBatchSize {
+			return archived, err
+		}
+	}
+}
+
+func (s *StockStore) archiveBatch(ctx context.Context, cutoff time.Time) (int64, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var moved int64
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var stocks []core.Stock
+
+		if err := tx.Unscoped().Where("time < ?", cutoff).Order("id").Limit(archiveBatchSize).Find(&stocks).Error; err != nil {
+			return err
+		}
+		if len(stocks) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(stocks))
+		entries := make([]core.AuditEntry, len(stocks))
+		for i := range stocks {
+			entry, err := core.NewAuditEntry(ctx, core.AuditActionArchive, &stocks[i], nil)
+			if err != nil {
+				return err
+			}
+			ids[i], entries[i] = stocks[i].ID, entry
+		}
+
+		insert := "INSERT INTO stocks_archive (" + stockColumns + ", archived_at) SELECT " + stockColumns + ", ? FROM stocks WHERE id IN ?"
+		if err := tx.Exec(insert, time.Now().UTC(), ids).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&core.Stock{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Create(&entries).Error; err != nil {
+			return err
+		}
+
+		moved = result.RowsAffected
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
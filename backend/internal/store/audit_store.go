@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"stockify/internal/core"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditQueryParams filters the audit log. Zero values match everything.
+type AuditQueryParams struct {
+	ActorType string
+	ActorID   string
+	Action    string
+	StockID   uint
+	Ticker    string
+	From      time.Time
+	To        time.Time
+	Page      int
+	PageSize  int
+}
+
+func recordAudit(ctx context.Context, tx *gorm.DB, action string, before, after *core.Stock) error {
+	entry, err := core.NewAuditEntry(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&entry).Error
+}
+
+// ListAuditEntries returns the audit entries matching params, newest first.
+func (s *StockStore) ListAuditEntries(ctx context.Context, params AuditQueryParams) ([]core.AuditEntry, int64, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var entries []core.AuditEntry
+	var totalItems int64
+
+	query := db.Model(&core.AuditEntry{})
+
+	if params.ActorType != "" {
+		query = query.Where("actor_type = ?", params.ActorType)
+	}
+	if params.ActorID != "" {
+		query = query.Where("actor_id = ?", params.ActorID)
+	}
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+	if params.StockID != 0 {
+		query = query.Where("stock_id = ?", params.StockID)
+	}
+	if params.Ticker != "" {
+		query = query.Where("UPPER(ticker) = ?", strings.ToUpper(params.Ticker))
+	}
+	if !params.From.IsZero() {
+		query = query.Where("created_at >= ?", params.From.UTC())
+	}
+	if !params.To.IsZero() {
+		query = query.Where("created_at < ?", params.To.UTC())
+	}
+
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("created_at DESC").Order("id DESC")
+	query = applyPagination(query, GetStocksParams{Page: params.Page, PageSize: params.PageSize})
+
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, totalItems, err
+	}
+
+	return entries, totalItems, nil
+}
+
+// matchesAudit reports whether entry passes the filters of params, with the
+// same semantics as the SQL query in ListAuditEntries.
+func matchesAudit(entry core.AuditEntry, params AuditQueryParams) bool {
+	switch {
+	case params.ActorType != "" && entry.ActorType != params.ActorType:
+		return false
+	case params.ActorID != "" && entry.ActorID != params.ActorID:
+		return false
+	case params.Action != "" && entry.Action != params.Action:
+		return false
+	case params.StockID != 0 && entry.StockID != params.StockID:
+		return false
+	case params.Ticker != "" && !strings.EqualFold(entry.Ticker, params.Ticker):
+		return false
+	case !params.From.IsZero() && entry.CreatedAt.Before(params.From):
+		return false
+	case !params.To.IsZero() && !entry.CreatedAt.Before(params.To):
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,323 @@
+package store
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"slices"
+	"stockify/internal/core"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStats is a snapshot of a CachingStore's counters.
+type CacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+}
+
+// CachingStore decorates a StockStoreInterface with a bounded LRU cache whose
+// entries expire after a TTL. Errors are never cached. Writes made through it
+// invalidate the cache; call Invalidate after the underlying data changes by
+// other means.
+type CachingStore struct {
+	next       StockStoreInterface
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	generation uint64
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type cacheEntry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+}
+
+type cachedPage[T any] struct {
+	rows  []T
+	total int64
+}
+
+// NewCachingStore wraps next with a cache holding at most maxEntries results
+// for ttl each.
+func NewCachingStore(next StockStoreInterface, maxEntries int, ttl time.Duration) *CachingStore {
+	return &CachingStore{
+		next:       next,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Invalidate drops every cached result. Loads already in flight when it is
+// called will not be stored.
+func (c *CachingStore) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.generation++
+}
+
+func (c *CachingStore) Stats() CacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Entries: entries}
+}
+
+func (c *CachingStore) get(key string) (any, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		if c.now().Before(entry.expiresAt) {
+			c.lru.MoveToFront(element)
+			return entry.value, c.generation, true
+		}
+		c.lru.Remove(element)
+		delete(c.entries, key)
+	}
+
+	return nil, c.generation, false
+}
+
+func (c *CachingStore) put(key string, value any, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if element, ok := c.entries[key]; ok {
+		c.lru.Remove(element)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, value: value, expiresAt: c.now().Add(c.ttl)})
+
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cached returns the result of load stored under key, calling load and
+// storing its result on a miss. Callers own what they get back, so results
+// are copied with clone both when stored and when returned; clone may be nil
+// for immutable values.
+func cached[T any](c *CachingStore, key string, load func() (T, error), clone func(T) T) (T, error) {
+	if c.maxEntries <= 0 || c.ttl <= 0 {
+		return load()
+	}
+	if clone == nil {
+		clone = func(v T) T { return v }
+	}
+
+	value, generation, ok := c.get(key)
+	if ok {
+		c.hits.Add(1)
+		return clone(value.(T)), nil
+	}
+	c.misses.Add(1)
+
+	result, err := load()
+	if err != nil {
+		return result, err
+	}
+	c.put(key, clone(result), generation)
+
+	return result, nil
+}
+
+// paramsKey encodes every field of params. Fields added to GetStocksParams
+// must be added here too, or requests differing only in them would share an
+// entry.
+func paramsKey(method string, params GetStocksParams) string {
+	return strings.Join([]string{
+		method,
+		strconv.Quote(params.Search),
+		strconv.Quote(params.SortBy),
+		strconv.Quote(params.SortOrder),
+		strconv.Itoa(params.Page),
+		strconv.Itoa(params.PageSize),
+		strconv.FormatBool(params.IncludeArchived),
+	}, ":")
+}
+
+func tickersKey(tickers []string, includeArchived bool) string {
+	parts := make([]string, 0, len(tickers)+2)
+	parts = append(parts, "GetStocksByTickers", strconv.FormatBool(includeArchived))
+	for _, ticker := range tickers {
+		parts = append(parts, strconv.Quote(ticker))
+	}
+	return strings.Join(parts, ":")
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func cloneStock(stock core.Stock) core.Stock {
+	stock.RatingFrom = clonePtr(stock.RatingFrom)
+	stock.TargetTo = clonePtr(stock.TargetTo)
+	stock.TargetFrom = clonePtr(stock.TargetFrom)
+	return stock
+}
+
+func cloneStockPtr(stock *core.Stock) *core.Stock {
+	if stock == nil {
+		return nil
+	}
+	clone := cloneStock(*stock)
+	return &clone
+}
+
+func cloneStocks(stocks []core.Stock) []core.Stock {
+	if stocks == nil {
+		return nil
+	}
+	clones := make([]core.Stock, len(stocks))
+	for i, stock := range stocks {
+		clones[i] = cloneStock(stock)
+	}
+	return clones
+}
+
+func cloneLatestStocks(stocks []core.LatestStock) []core.LatestStock {
+	if stocks == nil {
+		return nil
+	}
+	clones := make([]core.LatestStock, len(stocks))
+	for i, stock := range stocks {
+		clones[i] = stock
+		clones[i].Stock = cloneStock(stock.Stock)
+	}
+	return clones
+}
+
+func (c *CachingStore) GetStocks(ctx context.Context, params GetStocksParams) ([]core.Stock, int64, error) {
+	page, err := cached(c, paramsKey("GetStocks", params), func() (cachedPage[core.Stock], error) {
+		stocks, total, err := c.next.GetStocks(ctx, params)
+		return cachedPage[core.Stock]{rows: stocks, total: total}, err
+	}, func(page cachedPage[core.Stock]) cachedPage[core.Stock] {
+		return cachedPage[core.Stock]{rows: cloneStocks(page.rows), total: page.total}
+	})
+	return page.rows, page.total, err
+}
+
+func (c *CachingStore) GetLatestStocks(ctx context.Context, params GetStocksParams) ([]core.LatestStock, int64, error) {
+	page, err := cached(c, paramsKey("GetLatestStocks", params), func() (cachedPage[core.LatestStock], error) {
+		stocks, total, err := c.next.GetLatestStocks(ctx, params)
+		return cachedPage[core.LatestStock]{rows: stocks, total: total}, err
+	}, func(page cachedPage[core.LatestStock]) cachedPage[core.LatestStock] {
+		return cachedPage[core.LatestStock]{rows: cloneLatestStocks(page.rows), total: page.total}
+	})
+	return page.rows, page.total, err
+}
+
+func (c *CachingStore) GetStockByTicker(ctx context.Context, ticker string, includeArchived bool) (*core.Stock, error) {
+	key := "GetStockByTicker:" + strconv.Quote(ticker) + ":" + strconv.FormatBool(includeArchived)
+	return cached(c, key, func() (*core.Stock, error) {
+		return c.next.GetStockByTicker(ctx, ticker, includeArchived)
+	}, cloneStockPtr)
+}
+
+func (c *CachingStore) GetStocksByTickers(ctx context.Context, tickers []string, includeArchived bool) ([]core.Stock, error) {
+	return cached(c, tickersKey(tickers, includeArchived), func() ([]core.Stock, error) {
+		return c.next.GetStocksByTickers(ctx, tickers, includeArchived)
+	}, cloneStocks)
+}
+
+func (c *CachingStore) GetBrokerages(ctx context.Context, search string) ([]BrokerageSummary, error) {
+	return cached(c, "GetBrokerages:"+strconv.Quote(search), func() ([]BrokerageSummary, error) {
+		return c.next.GetBrokerages(ctx, search)
+	}, slices.Clone[[]BrokerageSummary])
+}
+
+func (c *CachingStore) CountStocks(ctx context.Context) (int64, error) {
+	return cached(c, "CountStocks", func() (int64, error) {
+		return c.next.CountStocks(ctx)
+	}, nil)
+}
+
+func (c *CachingStore) LastModified(ctx context.Context) (time.Time, error) {
+	return cached(c, "LastModified", func() (time.Time, error) {
+		return c.next.LastModified(ctx)
+	}, nil)
+}
+
+func (c *CachingStore) GetRawStocksForRecommendation(ctx context.Context, limit int) ([]core.Stock, error) {
+	return cached(c, fmt.Sprintf("GetRawStocksForRecommendation:%d", limit), func() ([]core.Stock, error) {
+		return c.next.GetRawStocksForRecommendation(ctx, limit)
+	}, cloneStocks)
+}
+
+// StreamStocks is not cached: exports may be arbitrarily large.
+func (c *CachingStore) StreamStocks(ctx context.Context, params GetStocksParams, fn func(core.Stock) error) error {
+	return c.next.StreamStocks(ctx, params, fn)
+}
+
+// GetStocksAfterID is not cached: it replays events to stream clients, which
+// must see every event as soon as it is created.
+func (c *CachingStore) GetStocksAfterID(ctx context.Context, afterID uint, limit int) ([]core.Stock, error) {
+	return c.next.GetStocksAfterID(ctx, afterID, limit)
+}
+
+// GetDeletedStocks is not cached: it backs admin views that must reflect
+// deletions immediately.
+func (c *CachingStore) GetDeletedStocks(ctx context.Context, params GetStocksParams) ([]core.Stock, int64, error) {
+	return c.next.GetDeletedStocks(ctx, params)
+}
+
+// SoftDeleteStock invalidates the cache even when the call fails, since the
+// row may have been deleted before the error occurred.
+func (c *CachingStore) SoftDeleteStock(ctx context.Context, id uint) (*core.Stock, error) {
+	defer c.Invalidate()
+	return c.next.SoftDeleteStock(ctx, id)
+}
+
+func (c *CachingStore) RestoreStock(ctx context.Context, id uint) (*core.Stock, error) {
+	defer c.Invalidate()
+	return c.next.RestoreStock(ctx, id)
+}
+
+func (c *CachingStore) PurgeStock(ctx context.Context, id uint) (bool, error) {
+	defer c.Invalidate()
+	return c.next.PurgeStock(ctx, id)
+}
+
+func (c *CachingStore) HasStockEvent(ctx context.Context, stock core.Stock) (bool, error) {
+	return c.next.HasStockEvent(ctx, stock)
+}
+
+func (c *CachingStore) CreateStocks(ctx context.Context, stocks ...*core.Stock) error {
+	defer c.Invalidate()
+	return c.next.CreateStocks(ctx, stocks...)
+}
+
+func (c *CachingStore) ArchiveStocks(ctx context.Context, cutoff time.Time) (int64, error) {
+	defer c.Invalidate()
+	return c.next.ArchiveStocks(ctx, cutoff)
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"stockify/internal/core"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingStore struct {
+	StockStoreInterface
+	calls int
+	err   error
+}
+
+func (c *countingStore) GetStocks(ctx context.Context, params GetStocksParams) ([]core.Stock, int64, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, 0, c.err
+	}
+	return c.StockStoreInterface.GetStocks(ctx, params)
+}
+
+func newCountingCache(maxEntries int) (*CachingStore, *countingStore, *time.Time) {
+	backend := &countingStore{StockStoreInterface: NewMemoryStore([]core.Stock{{Ticker: "AAPL"}, {Ticker: "MSFT"}})}
+	cache := NewCachingStore(backend, maxEntries, time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	return cache, backend, &now
+}
+
+func TestCachingStore_HitsAndExpiry(t *testing.T) {
+	cache, backend, now := newCountingCache(10)
+	ctx := context.Background()
+	params := GetStocksParams{Page: 1, PageSize: 10}
+
+	first, total, err := cache.GetStocks(ctx, params)
+	require.NoError(t, err)
+	second, _, err := cache.GetStocks(ctx, params)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, backend.calls)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Entries: 1}, cache.Stats())
+
+	*now = now.Add(2 * time.Minute)
+	_, _, err = cache.GetStocks(ctx, params)
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.calls, "expired entries are reloaded")
+}
+
+func TestCachingStore_Invalidate(t *testing.T) {
+	cache, backend, _ := newCountingCache(10)
+	ctx := context.Background()
+
+	_, _, _ = cache.GetStocks(ctx, GetStocksParams{})
+	cache.Invalidate()
+	_, _, _ = cache.GetStocks(ctx, GetStocksParams{})
+
+	assert.Equal(t, 2, backend.calls)
+}
+
+func TestCachingStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache, backend, _ := newCountingCache(2)
+	ctx := context.Background()
+	pageOne, pageTwo, pageThree := GetStocksParams{Page: 1}, GetStocksParams{Page: 2}, GetStocksParams{Page: 3}
+
+	_, _, _ = cache.GetStocks(ctx, pageOne)
+	_, _, _ = cache.GetStocks(ctx, pageTwo)
+	_, _, _ = cache.GetStocks(ctx, pageOne)
+	_, _, _ = cache.GetStocks(ctx, pageThree)
+	assert.Equal(t, 2, cache.Stats().Entries)
+
+	_, _, _ = cache.GetStocks(ctx, pageOne)
+	assert.Equal(t, 3, backend.calls, "page one was used recently and must still be cached")
+
+	_, _, _ = cache.GetStocks(ctx, pageTwo)
+	assert.Equal(t, 4, backend.calls, "page two was the least recently used entry")
+}
+
+func TestCachingStore_DoesNotCacheErrors(t *testing.T) {
+	cache, backend, _ := newCountingCache(10)
+	backend.err = errors.New("database down")
+	ctx := context.Background()
+
+	_, _, err := cache.GetStocks(ctx, GetStocksParams{})
+	assert.Error(t, err)
+
+	backend.err = nil
+	_, _, err = cache.GetStocks(ctx, GetStocksParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, backend.calls)
+}
+
+func TestCachingStore_ReturnsCopies(t *testing.T) {
+	target := 150.0
+	backend := NewMemoryStore([]core.Stock{{Ticker: "AAPL", TargetTo: &target}})
+	cache := NewCachingStore(backend, 10, time.Minute)
+	ctx := context.Background()
+
+	_, _, err := cache.GetStocks(ctx, GetStocksParams{})
+	require.NoError(t, err)
+	hit, _, err := cache.GetStocks(ctx, GetStocksParams{})
+	require.NoError(t, err)
+	hit[0].Ticker = "MSFT"
+	*hit[0].TargetTo = 0
+
+	again, _, err := cache.GetStocks(ctx, GetStocksParams{})
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", again[0].Ticker)
+	assert.Equal(t, 150.0, *again[0].TargetTo)
+
+	_, err = cache.GetStockByTicker(ctx, "AAPL", false)
+	require.NoError(t, err)
+	stock, err := cache.GetStockByTicker(ctx, "AAPL", false)
+	require.NoError(t, err)
+	*stock.TargetTo = 0
+
+	stock, err = cache.GetStockByTicker(ctx, "AAPL", false)
+	require.NoError(t, err)
+	assert.Equal(t, 150.0, *stock.TargetTo)
+}
+
+func TestCachingStore_KeysDistinguishEveryParam(t *testing.T) {
+	cache, backend, _ := newCountingCache(10)
+	ctx := context.Background()
+
+	for _, params := range []GetStocksParams{
+		{Search: "a"},
+		{Search: "a", SortBy: "ticker"},
+		{Search: "a", IncludeArchived: true},
+		{Search: "a", SortOrder: "desc"},
+	} {
+		_, _, err := cache.GetStocks(ctx, params)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 4, backend.calls)
+}
+
+func TestCachingStore_PurgeInvalidates(t *testing.T) {
+	cache, backend, _ := newCountingCache(10)
+	ctx := context.Background()
+
+	_, _, _ = cache.GetStocks(ctx, GetStocksParams{})
+	_, err := cache.PurgeStock(ctx, 1)
+	require.NoError(t, err)
+	_, _, _ = cache.GetStocks(ctx, GetStocksParams{})
+
+	assert.Equal(t, 2, backend.calls)
+}
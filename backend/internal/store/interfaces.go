@@ -1,10 +1,50 @@
 package store
 
-import "stockify/internal/core"
+import (
+	"context"
+	"stockify/internal/core"
+	"time"
+)
 
 type StockStoreInterface interface {
-	GetStocks(params GetStocksParams) ([]core.Stock, int64, error)
-	GetStockByTicker(ticker string) (*core.Stock, error)
-	CountStocks() (int64, error)
-	GetRawStocksForRecommendation(limit int) ([]core.Stock, error)
+	GetStocks(ctx context.Context, params GetStocksParams) ([]core.Stock, int64, error)
+	StreamStocks(ctx context.Context, params GetStocksParams, fn func(core.Stock) error) error
+	GetLatestStocks(ctx context.Context, params GetStocksParams) ([]core.LatestStock, int64, error)
+	GetStockByTicker(ctx context.Context, ticker string, includeArchived bool) (*core.Stock, error)
+	GetStocksByTickers(ctx context.Context, tickers []string, includeArchived bool) ([]core.Stock, error)
+	GetStocksAfterID(ctx context.Context, afterID uint, limit int) ([]core.Stock, error)
+	GetBrokerages(ctx context.Context, search string) ([]BrokerageSummary, error)
+	CountStocks(ctx context.Context) (int64, error)
+	LastModified(ctx context.Context) (time.Time, error)
+	GetRawStocksForRecommendation(ctx context.Context, limit int) ([]core.Stock, error)
+	GetDeletedStocks(ctx context.Context, params GetStocksParams) ([]core.Stock, int64, error)
+	SoftDeleteStock(ctx context.Context, id uint) (*core.Stock, error)
+	RestoreStock(ctx context.Context, id uint) (*core.Stock, error)
+	PurgeStock(ctx context.Context, id uint) (bool, error)
+	HasStockEvent(ctx context.Context, stock core.Stock) (bool, error)
+	CreateStocks(ctx context.Context, stocks ...*core.Stock) error
+	ArchiveStocks(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type AuditStoreInterface interface {
+	ListAuditEntries(ctx context.Context, params AuditQueryParams) ([]core.AuditEntry, int64, error)
+}
+
+// SyncStoreInterface keeps the record of data sync runs.
+type SyncStoreInterface interface {
+	RecordSyncRun(ctx context.Context, run *core.SyncRun) error
+	// LastSyncRun returns the most recent successful run, or nil if the sync
+	// never succeeded.
+	LastSyncRun(ctx context.Context) (*core.SyncRun, error)
+	SyncStats(ctx context.Context) (core.SyncStats, error)
+}
+
+// StockCounterInterface counts the visible rating events.
+type StockCounterInterface interface {
+	CountStocks(ctx context.Context) (int64, error)
+}
+
+// PingerInterface checks that the database answers.
+type PingerInterface interface {
+	Ping(ctx context.Context) error
 }
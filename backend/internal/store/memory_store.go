@@ -0,0 +1,696 @@
+package store
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"stockify/internal/core"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed fixtures/demo_stocks.json
+var demoFixture []byte
+
+// DemoFixture returns the stock events bundled for the server's demo mode.
+func DemoFixture() io.Reader {
+	return bytes.NewReader(demoFixture)
+}
+
+// MemoryStore is an in-memory StockStoreInterface with the same search,
+// sorting and pagination semantics as StockStore. It backs tests and the
+// server's demo mode.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	stocks       []core.Stock
+	archived     []core.Stock
+	nextID       uint
+	audit        []core.AuditEntry
+	syncRuns     []core.SyncRun
+	lastModified time.Time
+}
+
+// NewMemoryStore returns a store holding a copy of stocks. Rows without an ID
+// get one assigned in order, as the database would on insert.
+func NewMemoryStore(stocks []core.Stock) *MemoryStore {
+	m := &MemoryStore{nextID: 1}
+	now := time.Now()
+
+	for _, stock := range stocks {
+		if stock.ID == 0 {
+			stock.ID = m.nextID
+		}
+		if stock.ID >= m.nextID {
+			m.nextID = stock.ID + 1
+		}
+		if stock.CreatedAt.IsZero() {
+			stock.CreatedAt = now
+		}
+		if stock.UpdatedAt.IsZero() {
+			stock.UpdatedAt = stock.CreatedAt
+		}
+		m.touch(stock.UpdatedAt)
+		m.stocks = append(m.stocks, stock)
+	}
+
+	return m
+}
+
+// LoadMemoryStore builds a MemoryStore from a JSON array of stocks, using the
+// same field names as the API.
+func LoadMemoryStore(r io.Reader) (*MemoryStore, error) {
+	var stocks []core.Stock
+	if err := json.NewDecoder(r).Decode(&stocks); err != nil {
+		return nil, fmt.Errorf("decoding stock fixture: %w", err)
+	}
+
+	return NewMemoryStore(stocks), nil
+}
+
+// visible returns the rows that are not soft-deleted and match search.
+func (m *MemoryStore) visible(search string) []core.Stock {
+	return m.filter(search, false, false)
+}
+
+// filter returns the rows matching search that are soft-deleted or not,
+// depending on deleted, looking at the archived rows too when
+// includeArchived is set.
+func (m *MemoryStore) filter(search string, deleted, includeArchived bool) []core.Stock {
+	search = strings.ToLower(search)
+
+	rows := m.stocks
+	if includeArchived {
+		rows = slices.Concat(m.stocks, m.archived)
+	}
+
+	var result []core.Stock
+	for _, stock := range rows {
+		if stock.DeletedAt.Valid != deleted {
+			continue
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(stock.Ticker), search) &&
+			!strings.Contains(strings.ToLower(stock.Company), search) {
+			continue
+		}
+		result = append(result, stock)
+	}
+
+	return result
+}
+
+func (m *MemoryStore) GetStocks(ctx context.Context, params GetStocksParams) ([]core.Stock, int64, error) {
+	return m.list(ctx, params, false)
+}
+
+func (m *MemoryStore) GetDeletedStocks(ctx context.Context, params GetStocksParams) ([]core.Stock, int64, error) {
+	return m.list(ctx, params, true)
+}
+
+// StreamStocks calls fn for every visible event matching the search and
+// sorting of params. Pagination is ignored.
+func (m *MemoryStore) StreamStocks(ctx context.Context, params GetStocksParams, fn func(core.Stock) error) error {
+	params.Page, params.PageSize = 0, 0
+
+	stocks, _, err := m.list(ctx, params, false)
+	if err != nil {
+		return err
+	}
+
+	for _, stock := range stocks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(stock); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) list(ctx context.Context, params GetStocksParams, deleted bool) ([]core.Stock, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rows := make([]core.LatestStock, 0, len(m.stocks))
+	for _, stock := range m.filter(params.Search, deleted, params.IncludeArchived && !deleted) {
+		rows = append(rows, core.LatestStock{Stock: stock})
+	}
+
+	if err := sortRows(rows, params, false); err != nil {
+		return nil, 0, err
+	}
+
+	page := paginate(rows, params)
+	stocks := make([]core.Stock, 0, len(page))
+	for _, row := range page {
+		stocks = append(stocks, row.Stock)
+	}
+
+	return stocks, int64(len(rows)), nil
+}
+
+func (m *MemoryStore) GetLatestStocks(ctx context.Context, params GetStocksParams) ([]core.LatestStock, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	latestByTicker := make(map[string]*core.LatestStock)
+	var order []string
+	for _, stock := range m.filter(params.Search, false, params.IncludeArchived) {
+		latest, ok := latestByTicker[stock.Ticker]
+		if !ok {
+			latestByTicker[stock.Ticker] = &core.LatestStock{Stock: stock, EventCount: 1}
+			order = append(order, stock.Ticker)
+			continue
+		}
+
+		latest.EventCount++
+		if stock.Time.After(latest.Time) || (stock.Time.Equal(latest.Time) && stock.ID > latest.ID) {
+			latest.Stock = stock
+		}
+	}
+
+	rows := make([]core.LatestStock, 0, len(order))
+	for _, ticker := range order {
+		rows = append(rows, *latestByTicker[ticker])
+	}
+
+	if err := sortRows(rows, params, true); err != nil {
+		return nil, 0, err
+	}
+
+	return paginate(rows, params), int64(len(rows)), nil
+}
+
+func (m *MemoryStore) GetStockByTicker(ctx context.Context, ticker string, includeArchived bool) (*core.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var found *core.Stock
+	for _, stock := range m.filter("", false, includeArchived) {
+		if !strings.EqualFold(stock.Ticker, ticker) {
+			continue
+		}
+		if found == nil || stock.ID < found.ID {
+			match := stock
+			found = &match
+		}
+	}
+
+	return found, nil
+}
+
+func (m *MemoryStore) GetStocksByTickers(ctx context.Context, tickers []string, includeArchived bool) ([]core.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(tickers))
+	for _, ticker := range tickers {
+		wanted[strings.ToUpper(ticker)] = true
+	}
+
+	var stocks []core.Stock
+	for _, stock := range m.filter("", false, includeArchived) {
+		if wanted[strings.ToUpper(stock.Ticker)] {
+			stocks = append(stocks, stock)
+		}
+	}
+
+	sort.SliceStable(stocks, func(i, j int) bool {
+		if !stocks[i].Time.Equal(stocks[j].Time) {
+			return stocks[i].Time.After(stocks[j].Time)
+		}
+		return stocks[i].ID > stocks[j].ID
+	})
+
+	return stocks, nil
+}
+
+func (m *MemoryStore) GetStocksAfterID(ctx context.Context, afterID uint, limit int) ([]core.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stocks []core.Stock
+	for _, stock := range m.visible("") {
+		if stock.ID > afterID {
+			stocks = append(stocks, stock)
+		}
+	}
+
+	sort.Slice(stocks, func(i, j int) bool { return stocks[i].ID < stocks[j].ID })
+	if limit > 0 && len(stocks) > limit {
+		stocks = stocks[:limit]
+	}
+
+	return stocks, nil
+}
+
+func (m *MemoryStore) GetBrokerages(ctx context.Context, search string) ([]BrokerageSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	search = strings.ToLower(search)
+	counts := make(map[string]int64)
+	for _, stock := range m.visible("") {
+		if strings.Contains(strings.ToLower(stock.Brokerage), search) {
+			counts[stock.Brokerage]++
+		}
+	}
+
+	brokerages := make([]BrokerageSummary, 0, len(counts))
+	for name, count := range counts {
+		brokerages = append(brokerages, BrokerageSummary{Name: name, EventCount: count})
+	}
+
+	sort.Slice(brokerages, func(i, j int) bool {
+		if brokerages[i].EventCount != brokerages[j].EventCount {
+			return brokerages[i].EventCount > brokerages[j].EventCount
+		}
+		return brokerages[i].Name < brokerages[j].Name
+	})
+
+	return brokerages, nil
+}
+
+func (m *MemoryStore) GetRawStocksForRecommendation(ctx context.Context, limit int) ([]core.Stock, error) {
+	stocks, _, err := m.GetStocks(ctx, GetStocksParams{PageSize: limit})
+	return stocks, err
+}
+
+func (m *MemoryStore) CountStocks(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return int64(len(m.visible(""))), nil
+}
+
+func (m *MemoryStore) indexOf(id uint) int {
+	for i, stock := range m.stocks {
+		if stock.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *MemoryStore) SoftDeleteStock(ctx context.Context, id uint) (*core.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 || m.stocks[i].DeletedAt.Valid {
+		return nil, nil
+	}
+
+	before := m.stocks[i]
+	m.stocks[i].DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	m.touch(m.stocks[i].DeletedAt.Time)
+	stock := m.stocks[i]
+
+	if err := m.recordAudit(ctx, core.AuditActionDelete, &before, &stock); err != nil {
+		return nil, err
+	}
+
+	return &stock, nil
+}
+
+func (m *MemoryStore) RestoreStock(ctx context.Context, id uint) (*core.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 || !m.stocks[i].DeletedAt.Valid {
+		return nil, nil
+	}
+
+	before := m.stocks[i]
+	m.stocks[i].DeletedAt = gorm.DeletedAt{}
+	m.stocks[i].UpdatedAt = time.Now()
+	m.touch(m.stocks[i].UpdatedAt)
+	stock := m.stocks[i]
+
+	if err := m.recordAudit(ctx, core.AuditActionUpdate, &before, &stock); err != nil {
+		return nil, err
+	}
+
+	return &stock, nil
+}
+
+func (m *MemoryStore) PurgeStock(ctx context.Context, id uint) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 || !m.stocks[i].DeletedAt.Valid {
+		return false, nil
+	}
+
+	before := m.stocks[i]
+	m.stocks = append(m.stocks[:i], m.stocks[i+1:]...)
+	m.touch(time.Now())
+
+	if err := m.recordAudit(ctx, core.AuditActionDelete, &before, nil); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (m *MemoryStore) HasStockEvent(ctx context.Context, stock core.Stock) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, existing := range slices.Concat(m.stocks, m.archived) {
+		if existing.Ticker == stock.Ticker && existing.Brokerage == stock.Brokerage &&
+			existing.Action == stock.Action && existing.Time.Equal(stock.Time) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *MemoryStore) CreateStocks(ctx context.Context, stocks ...*core.Stock) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, stock := range stocks {
+		stock.ID = m.nextID
+		stock.CreatedAt, stock.UpdatedAt = now, now
+		m.nextID++
+		m.stocks = append(m.stocks, *stock)
+
+		if err := m.recordAudit(ctx, core.AuditActionCreate, nil, stock); err != nil {
+			return err
+		}
+	}
+	if len(stocks) > 0 {
+		m.touch(now)
+	}
+
+	return nil
+}
+
+// ArchiveStocks moves every event whose time is before cutoff, including
+// soft-deleted ones, to the archive, recording an archive audit entry for
+// each.
+func (m *MemoryStore) ArchiveStocks(ctx context.Context, cutoff time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var kept, moved []core.Stock
+	for _, stock := range m.stocks {
+		if stock.Time.Before(cutoff) {
+			moved = append(moved, stock)
+			continue
+		}
+		kept = append(kept, stock)
+	}
+	m.stocks = kept
+	m.archived = append(m.archived, moved...)
+	if len(moved) > 0 {
+		m.touch(time.Now())
+	}
+
+	for i := range moved {
+		if err := m.recordAudit(ctx, core.AuditActionArchive, &moved[i], nil); err != nil {
+			return int64(len(moved)), err
+		}
+	}
+
+	return int64(len(moved)), nil
+}
+
+// touch records t as the time of the latest change if it is more recent.
+// Callers must hold the write lock.
+func (m *MemoryStore) touch(t time.Time) {
+	if t.After(m.lastModified) {
+		m.lastModified = t
+	}
+}
+
+func (m *MemoryStore) LastModified(ctx context.Context) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.lastModified, nil
+}
+
+// recordAudit appends an audit entry. Callers must hold the write lock.
+func (m *MemoryStore) recordAudit(ctx context.Context, action string, before, after *core.Stock) error {
+	entry, err := core.NewAuditEntry(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	entry.ID = uint(len(m.audit) + 1)
+	m.audit = append(m.audit, entry)
+	return nil
+}
+
+func (m *MemoryStore) ListAuditEntries(ctx context.Context, params AuditQueryParams) ([]core.AuditEntry, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := []core.AuditEntry{}
+	for i := len(m.audit) - 1; i >= 0; i-- {
+		if matchesAudit(m.audit[i], params) {
+			entries = append(entries, m.audit[i])
+		}
+	}
+
+	total := int64(len(entries))
+	if params.PageSize > 0 {
+		offset := 0
+		if params.Page > 0 {
+			offset = min((params.Page-1)*params.PageSize, len(entries))
+		}
+		entries = entries[offset:min(offset+params.PageSize, len(entries))]
+	}
+
+	return entries, total, nil
+}
+
+// compareColumn compares a and b on a stocks column. NULL values sort before
+// any other value, as they do in CockroachDB.
+func compareColumn(a, b core.LatestStock, column string, allowEventCount bool) (int, error) {
+	switch column {
+	case "id":
+		return cmp.Compare(a.ID, b.ID), nil
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt), nil
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt), nil
+	case "ticker":
+		return strings.Compare(a.Ticker, b.Ticker), nil
+	case "company":
+		return strings.Compare(a.Company, b.Company), nil
+	case "brokerage":
+		return strings.Compare(a.Brokerage, b.Brokerage), nil
+	case "action":
+		return strings.Compare(a.Action, b.Action), nil
+	case "rating_to":
+		return strings.Compare(a.RatingTo, b.RatingTo), nil
+	case "rating_from":
+		return compareNullable(a.RatingFrom, b.RatingFrom), nil
+	case "target_to":
+		return compareNullable(a.TargetTo, b.TargetTo), nil
+	case "target_from":
+		return compareNullable(a.TargetFrom, b.TargetFrom), nil
+	case "time":
+		return a.Time.Compare(b.Time), nil
+	case "event_count":
+		if allowEventCount {
+			return cmp.Compare(a.EventCount, b.EventCount), nil
+		}
+	}
+
+	return 0, fmt.Errorf("column %q does not exist", column)
+}
+
+func compareNullable[T cmp.Ordered](a, b *T) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	default:
+		return cmp.Compare(*a, *b)
+	}
+}
+
+func sortRows(rows []core.LatestStock, params GetStocksParams, allowEventCount bool) error {
+	column, descending, err := resolveSort(params, allowEventCount)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		result, _ := compareColumn(rows[i], rows[j], column, allowEventCount)
+		if result == 0 {
+			return rows[i].ID < rows[j].ID
+		}
+		if descending {
+			return result > 0
+		}
+		return result < 0
+	})
+
+	return nil
+}
+
+func paginate(rows []core.LatestStock, params GetStocksParams) []core.LatestStock {
+	if params.PageSize <= 0 {
+		return rows
+	}
+
+	offset := 0
+	if params.Page > 0 {
+		offset = (params.Page - 1) * params.PageSize
+	}
+	if offset >= len(rows) {
+		return []core.LatestStock{}
+	}
+
+	end := min(offset+params.PageSize, len(rows))
+	return rows[offset:end]
+}
+
+func (m *MemoryStore) RecordSyncRun(ctx context.Context, run *core.SyncRun) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run.ID = uint(len(m.syncRuns) + 1)
+	m.syncRuns = append(m.syncRuns, *run)
+	return nil
+}
+
+func (m *MemoryStore) LastSyncRun(ctx context.Context) (*core.SyncRun, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.lastSyncRun(), nil
+}
+
+// lastSyncRun returns the successful run that finished last. m.mu must be
+// held.
+func (m *MemoryStore) lastSyncRun() *core.SyncRun {
+	var last *core.SyncRun
+	for i := range m.syncRuns {
+		if !m.syncRuns[i].Succeeded() {
+			continue
+		}
+		if last == nil || m.syncRuns[i].FinishedAt.After(last.FinishedAt) {
+			run := m.syncRuns[i]
+			last = &run
+		}
+	}
+	return last
+}
+
+func (m *MemoryStore) SyncStats(ctx context.Context) (core.SyncStats, error) {
+	if err := ctx.Err(); err != nil {
+		return core.SyncStats{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := core.SyncStats{LastSuccess: m.lastSyncRun()}
+	for _, run := range m.syncRuns {
+		stats.Pages += int64(run.Pages)
+		stats.Created += int64(run.Created)
+		stats.Skipped += int64(run.Skipped)
+		stats.Failed += int64(run.Failed)
+		if !run.Succeeded() {
+			stats.FailedRuns++
+		}
+	}
+	return stats, nil
+}
+
+// Ping always succeeds while ctx is alive, as there is no database.
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
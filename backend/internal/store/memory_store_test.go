@@ -0,0 +1,67 @@
+package store_test
+
+import (
+	"context"
+	"stockify/internal/core"
+	"stockify/internal/store"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLoadMemoryStore_DemoFixture(t *testing.T) {
+	s, err := store.LoadMemoryStore(store.DemoFixture())
+	require.NoError(t, err)
+
+	count, err := s.CountStocks(context.Background())
+	require.NoError(t, err)
+	assert.Greater(t, count, int64(0))
+
+	stock, err := s.GetStockByTicker(context.Background(), "aapl", false)
+	require.NoError(t, err)
+	require.NotNil(t, stock)
+	assert.NotZero(t, stock.ID)
+	assert.False(t, stock.Time.IsZero())
+}
+
+func TestLoadMemoryStore_InvalidJSON(t *testing.T) {
+	_, err := store.LoadMemoryStore(strings.NewReader(`{"ticker": "AAPL"}`))
+	assert.Error(t, err)
+}
+
+func TestMemoryStore_ExcludesSoftDeleted(t *testing.T) {
+	stocks := testStocks()
+	stocks[1].DeletedAt = gorm.DeletedAt{Time: baseTime, Valid: true}
+	s := store.NewMemoryStore(stocks)
+	ctx := context.Background()
+
+	count, err := s.CountStocks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	stock, err := s.GetStockByTicker(ctx, "MSFT", false)
+	require.NoError(t, err)
+	assert.Nil(t, stock)
+}
+
+func TestMemoryStore_UnknownSortColumn(t *testing.T) {
+	s := store.NewMemoryStore(testStocks())
+
+	_, _, err := s.GetStocks(context.Background(), store.GetStocksParams{SortBy: "event_count"})
+	assert.Error(t, err, "event_count only exists in the latest view")
+
+	_, _, err = s.GetLatestStocks(context.Background(), store.GetStocksParams{SortBy: "event_count", SortOrder: "desc"})
+	assert.NoError(t, err)
+}
+
+func TestMemoryStore_CancelledContext(t *testing.T) {
+	s := store.NewMemoryStore([]core.Stock{{Ticker: "AAPL"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := s.GetStocks(ctx, store.GetStocksParams{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortColumns are the columns rating events can be sorted by. They are the
+// only values of GetStocksParams.SortBy that reach a query.
+var SortColumns = []string{"id", "created_at", "updated_at", "ticker", "company", "brokerage", "action", "rating_to", "rating_from", "target_to", "target_from", "time"}
+
+// EventCountColumn sorts the latest view by the number of events of each
+// ticker. Only GetLatestStocks accepts it.
+const EventCountColumn = "event_count"
+
+// SortOrders are the accepted values of GetStocksParams.SortOrder, besides
+// the empty default.
+var SortOrders = []string{"asc", "desc"}
+
+// InvalidSortError is returned by the list methods when the sort column or
+// order of GetStocksParams is not allowed.
+type InvalidSortError struct {
+	// Field is the GetStocksParams field at fault, SortBy or SortOrder.
+	Field   string
+	Value   string
+	Allowed []string
+}
+
+func (e *InvalidSortError) Error() string {
+	return fmt.Sprintf("invalid %s %q, allowed values: %s", e.Field, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// SortColumnsFor returns the columns accepted as SortBy, including
+// EventCountColumn if allowEventCount is set.
+func SortColumnsFor(allowEventCount bool) []string {
+	if allowEventCount {
+		return append(slices.Clip(SortColumns), EventCountColumn)
+	}
+	return SortColumns
+}
+
+// ValidateSort returns an *InvalidSortError if the sorting of params is not
+// allowed, so that callers can reject it before querying the store.
+func ValidateSort(params GetStocksParams, allowEventCount bool) error {
+	_, _, err := resolveSort(params, allowEventCount)
+	return err
+}
+
+// resolveSort validates the sorting of params, both matched
+// case-insensitively, and returns the column to sort by and whether the order
+// is descending. Without a column, events are sorted newest first whatever
+// the order.
+func resolveSort(params GetStocksParams, allowEventCount bool) (string, bool, error) {
+	order := strings.ToLower(params.SortOrder)
+	if order != "" && !slices.Contains(SortOrders, order) {
+		return "", false, &InvalidSortError{Field: "SortOrder", Value: params.SortOrder, Allowed: SortOrders}
+	}
+
+	if params.SortBy == "" {
+		return "time", true, nil
+	}
+
+	column := strings.ToLower(params.SortBy)
+	allowed := SortColumnsFor(allowEventCount)
+	if !slices.Contains(allowed, column) {
+		return "", false, &InvalidSortError{Field: "SortBy", Value: params.SortBy, Allowed: allowed}
+	}
+
+	return column, order == "desc", nil
+}
@@ -1,18 +1,34 @@
 package store
 
 import (
+	"context"
 	"stockify/internal/core"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type StockStore struct {
-	db *gorm.DB
+	db           *gorm.DB
+	queryTimeout time.Duration
 }
 
-func NewStockStore(db *gorm.DB) *StockStore {
-	return &StockStore{db: db}
+// NewStockStore creates a store backed by db. Every query is bounded by
+// queryTimeout on top of the caller's context; zero disables the bound.
+func NewStockStore(db *gorm.DB, queryTimeout time.Duration) *StockStore {
+	return &StockStore{db: db, queryTimeout: queryTimeout}
+}
+
+// conn returns a session bound to ctx and the configured query timeout. The
+// returned cancel function must be called once the query has finished.
+func (s *StockStore) conn(ctx context.Context) (*gorm.DB, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return s.db.WithContext(ctx), func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.queryTimeout)
+	return s.db.WithContext(ctx), cancel
 }
 
 type GetStocksParams struct {
@@ -21,44 +37,121 @@ type GetStocksParams struct {
 	SortOrder string
 	Page      int
 	PageSize  int
+	// IncludeArchived also lists the events moved to the archive by the
+	// retention policy. Only GetStocks, GetLatestStocks and StreamStocks
+	// honor it.
+	IncludeArchived bool
+}
+
+func (s *StockStore) GetStocks(ctx context.Context, params GetStocksParams) ([]core.Stock, int64, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	return listStocks(stockRows(db, params.IncludeArchived), params)
+}
+
+// GetDeletedStocks lists the soft-deleted rating events with the same search,
+// sorting and pagination rules as GetStocks.
+func (s *StockStore) GetDeletedStocks(ctx context.Context, params GetStocksParams) ([]core.Stock, int64, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	return listStocks(db.Unscoped().Model(&core.Stock{}).Where("deleted_at IS NOT NULL"), params)
+}
+
+// StreamStocks calls fn for every event matching the search and sorting of
+// params, reading them from the database one row at a time so that the whole
+// result is never held in memory. Pagination is ignored. The query timeout
+// does not apply, since the stream lasts as long as fn keeps consuming rows;
+// cancel ctx to stop it.
+func (s *StockStore) StreamStocks(ctx context.Context, params GetStocksParams, fn func(core.Stock) error) error {
+	db := s.db.WithContext(ctx)
+
+	column, descending, err := resolveSort(params, false)
+	if err != nil {
+		return err
+	}
+
+	query := applySearch(stockRows(db, params.IncludeArchived), params)
+	query = applySorting(query, column, descending)
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stock core.Stock
+
+		if err := db.ScanRows(rows, &stock); err != nil {
+			return err
+		}
+		if err := fn(stock); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
-func (s *StockStore) GetStocks(params GetStocksParams) ([]core.Stock, int64, error) {
+func listStocks(query *gorm.DB, params GetStocksParams) ([]core.Stock, int64, error) {
 	var stocks []core.Stock
 	var totalItems int64
 
-	query := s.db.Model(&core.Stock{})
-
-	if params.Search != "" {
-		searchTerm := "%" + strings.ToLower(params.Search) + "%"
-		query = query.Where("LOWER(ticker) LIKE ? OR LOWER(company) LIKE ?", searchTerm, searchTerm)
+	column, descending, err := resolveSort(params, false)
+	if err != nil {
+		return nil, 0, err
 	}
 
+	query = applySearch(query, params)
+
 	if err := query.Count(&totalItems).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if params.SortBy != "" {
-		order := params.SortBy
+	query = applySorting(query, column, descending)
+	query = applyPagination(query, params)
 
-		if strings.ToLower(params.SortOrder) == "desc" {
-			order += " DESC"
-		} else {
-			order += " ASC"
-		}
+	if err := query.Find(&stocks).Error; err != nil {
+		return nil, totalItems, err
+	}
 
-		query = query.Order(order)
-	} else {
-		query = query.Order("time DESC")
+	return stocks, totalItems, nil
+}
+
+// GetLatestStocks returns one row per ticker: its most recent rating event and
+// the number of events recorded for it. Search, sorting and pagination apply
+// to the collapsed rows.
+func (s *StockStore) GetLatestStocks(ctx context.Context, params GetStocksParams) ([]core.LatestStock, int64, error) {
+	column, descending, err := resolveSort(params, true)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if params.Page > 0 && params.PageSize > 0 {
-		offset := (params.Page - 1) * params.PageSize
-		query = query.Limit(params.PageSize).Offset(offset)
-	} else if params.PageSize > 0 {
-		query = query.Limit(params.PageSize).Offset(0)
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var stocks []core.LatestStock
+	var totalItems int64
+
+	ranked := stockRows(db, params.IncludeArchived).Select(
+		"stocks.*, " +
+			"ROW_NUMBER() OVER (PARTITION BY ticker ORDER BY time DESC, id DESC) AS event_rank, " +
+			"COUNT(*) OVER (PARTITION BY ticker) AS event_count",
+	)
+
+	ranked = applySearch(ranked, params)
+
+	query := db.Table("(?) AS latest", ranked).Where("event_rank = 1")
+
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
 	}
 
+	query = applySorting(query, column, descending)
+	query = applyPagination(query, params)
+
 	if err := query.Find(&stocks).Error; err != nil {
 		return nil, totalItems, err
 	}
@@ -66,10 +159,53 @@ func (s *StockStore) GetStocks(params GetStocksParams) ([]core.Stock, int64, err
 	return stocks, totalItems, nil
 }
 
-func (s *StockStore) GetStockByTicker(ticker string) (*core.Stock, error) {
+func applySearch(query *gorm.DB, params GetStocksParams) *gorm.DB {
+	if params.Search == "" {
+		return query
+	}
+
+	searchTerm := "%" + strings.ToLower(params.Search) + "%"
+	return query.Where("LOWER(ticker) LIKE ? OR LOWER(company) LIKE ?", searchTerm, searchTerm)
+}
+
+// applySorting orders query by column, which must come from resolveSort since
+// it is part of the SQL text.
+// applySorting orders query by column, breaking ties by ascending ID as the
+// MemoryStore does, so that pages are stable when many rows share a value.
+func applySorting(query *gorm.DB, column string, descending bool) *gorm.DB {
+	direction := " ASC"
+	if descending {
+		direction = " DESC"
+	}
+
+	query = query.Order(column + direction)
+	if column != "id" {
+		query = query.Order("id ASC")
+	}
+	return query
+}
+
+func applyPagination(query *gorm.DB, params GetStocksParams) *gorm.DB {
+	if params.Page > 0 && params.PageSize > 0 {
+		offset := (params.Page - 1) * params.PageSize
+		return query.Limit(params.PageSize).Offset(offset)
+	} else if params.PageSize > 0 {
+		return query.Limit(params.PageSize).Offset(0)
+	}
+
+	return query
+}
+
+// GetStockByTicker returns the visible event of ticker with the lowest ID,
+// matched case-insensitively, looking in the archive too if includeArchived is
+// set.
+func (s *StockStore) GetStockByTicker(ctx context.Context, ticker string, includeArchived bool) (*core.Stock, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
 	var stock core.Stock
 
-	if err := s.db.Where("UPPER(ticker) = ?", strings.ToUpper(ticker)).First(&stock).Error; err != nil {
+	if err := stockRows(db, includeArchived).Where("UPPER(ticker) = ?", strings.ToUpper(ticker)).First(&stock).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -80,9 +216,87 @@ func (s *StockStore) GetStockByTicker(ticker string) (*core.Stock, error) {
 	return &stock, nil
 }
 
-func (s *StockStore) GetRawStocksForRecommendation(limit int) ([]core.Stock, error) {
+// GetStocksByTickers returns every visible event of the given tickers, newest
+// first, in a single query, looking in the archive too if includeArchived is
+// set. Tickers are matched case-insensitively.
+func (s *StockStore) GetStocksByTickers(ctx context.Context, tickers []string, includeArchived bool) ([]core.Stock, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var stocks []core.Stock
+
+	if len(tickers) == 0 {
+		return stocks, nil
+	}
+
+	upper := make([]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		upper = append(upper, strings.ToUpper(ticker))
+	}
+
+	if err := stockRows(db, includeArchived).Where("UPPER(ticker) IN ?", upper).Order("time DESC, id DESC").Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+// GetStocksAfterID returns up to limit visible events whose ID is greater than
+// afterID, in ID order, or all of them if limit is not positive. Paging
+// through it with the last ID seen replays every event created after a given
+// one.
+func (s *StockStore) GetStocksAfterID(ctx context.Context, afterID uint, limit int) ([]core.Stock, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var stocks []core.Stock
+
+	query := db.Where("id > ?", afterID).Order("id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+// BrokerageSummary is a brokerage together with the number of visible events
+// it issued.
+type BrokerageSummary struct {
+	Name       string
+	EventCount int64
+}
+
+// GetBrokerages lists the brokerages whose name contains search, the most
+// active first.
+func (s *StockStore) GetBrokerages(ctx context.Context, search string) ([]BrokerageSummary, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var brokerages []BrokerageSummary
+
+	query := db.Model(&core.Stock{}).Select("brokerage AS name, COUNT(*) AS event_count").Group("brokerage")
+
+	if search != "" {
+		query = query.Where("LOWER(brokerage) LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+
+	if err := query.Order("event_count DESC, brokerage ASC").Scan(&brokerages).Error; err != nil {
+		return nil, err
+	}
+
+	return brokerages, nil
+}
+
+func (s *StockStore) GetRawStocksForRecommendation(ctx context.Context, limit int) ([]core.Stock, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
 	var stocks []core.Stock
-	query := s.db.Model(&core.Stock{}).Order("time DESC")
+	query := db.Model(&core.Stock{}).Order("time DESC")
 
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -94,12 +308,207 @@ func (s *StockStore) GetRawStocksForRecommendation(limit int) ([]core.Stock, err
 	return stocks, nil
 }
 
-func (s *StockStore) CountStocks() (int64, error) {
+func (s *StockStore) CountStocks(ctx context.Context) (int64, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
 	var count int64
 
-	if err := s.db.Model(&core.Stock{}).Count(&count).Error; err != nil {
+	if err := db.Model(&core.Stock{}).Count(&count).Error; err != nil {
 		return 0, err
 	}
 
 	return count, nil
 }
+
+// lastModifiedColumns are the timestamps that together record every change
+// to the rating events: inserts and updates, soft deletes, purges (only
+// visible in the audit log) and archivals. Each of them is indexed, so that
+// LastModified reads a single index entry per column.
+var lastModifiedColumns = []struct{ table, column string }{
+	{"stocks", "updated_at"},
+	{"stocks", "deleted_at"},
+	{"audit_log", "created_at"},
+	{"stocks_archive", "archived_at"},
+}
+
+// LastModified returns when the rating events last changed. It is zero if
+// nothing was ever stored.
+func (s *StockStore) LastModified(ctx context.Context) (time.Time, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var lastModified time.Time
+
+	for _, source := range lastModifiedColumns {
+		var latest []time.Time
+
+		err := db.Table(source.table).
+			Where(source.column+" IS NOT NULL").
+			Order(source.column+" DESC").
+			Limit(1).
+			Pluck(source.column, &latest).Error
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if len(latest) > 0 && latest[0].After(lastModified) {
+			lastModified = latest[0]
+		}
+	}
+
+	return lastModified, nil
+}
+
+// HasStockEvent reports whether an event with the same ticker, brokerage,
+// action and time is already stored, including soft-deleted and archived
+// ones, so that a re-sync does not bring back events an admin removed or the
+// retention policy archived.
+func (s *StockStore) HasStockEvent(ctx context.Context, stock core.Stock) (bool, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var existing int64
+
+	err := stockRows(db, true).Unscoped().
+		Where("ticker = ? AND brokerage = ? AND action = ? AND time = ?", stock.Ticker, stock.Brokerage, stock.Action, stock.Time).
+		Count(&existing).Error
+
+	return existing > 0, err
+}
+
+// CreateStocks inserts stocks and records their creation in the audit log in
+// a single transaction, so that either all of them are stored or none.
+func (s *StockStore) CreateStocks(ctx context.Context, stocks ...*core.Stock) error {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, stock := range stocks {
+			if err := tx.Create(stock).Error; err != nil {
+				return err
+			}
+
+			if err := recordAudit(ctx, tx, core.AuditActionCreate, nil, stock); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// SoftDeleteStock marks the rating event id as deleted and returns it, or nil
+// if no visible event has that id.
+func (s *StockStore) SoftDeleteStock(ctx context.Context, id uint) (*core.Stock, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var deleted *core.Stock
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var before core.Stock
+
+		if err := tx.First(&before, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+
+			return err
+		}
+
+		if err := tx.Delete(&core.Stock{}, id).Error; err != nil {
+			return err
+		}
+
+		after, err := findUnscoped(tx, id)
+		if err != nil {
+			return err
+		}
+
+		deleted = after
+		return recordAudit(ctx, tx, core.AuditActionDelete, &before, after)
+	})
+
+	return deleted, err
+}
+
+// RestoreStock clears the deletion mark of the soft-deleted event id and
+// returns it, or nil if no deleted event has that id.
+func (s *StockStore) RestoreStock(ctx context.Context, id uint) (*core.Stock, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var restored *core.Stock
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := findDeleted(tx, id)
+		if err != nil || before == nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&core.Stock{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		after, err := findUnscoped(tx, id)
+		if err != nil {
+			return err
+		}
+
+		restored = after
+		return recordAudit(ctx, tx, core.AuditActionUpdate, before, after)
+	})
+
+	return restored, err
+}
+
+// PurgeStock permanently removes the soft-deleted event id. It reports false
+// if no deleted event has that id; visible events cannot be purged.
+func (s *StockStore) PurgeStock(ctx context.Context, id uint) (bool, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	purged := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := findDeleted(tx, id)
+		if err != nil || before == nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&core.Stock{}, id).Error; err != nil {
+			return err
+		}
+
+		purged = true
+		return recordAudit(ctx, tx, core.AuditActionDelete, before, nil)
+	})
+
+	return purged, err
+}
+
+func findUnscoped(db *gorm.DB, id uint) (*core.Stock, error) {
+	var stock core.Stock
+
+	if err := db.Unscoped().First(&stock, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &stock, nil
+}
+
+// findDeleted returns the soft-deleted event id, or nil if there is none.
+func findDeleted(db *gorm.DB, id uint) (*core.Stock, error) {
+	var stock core.Stock
+
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&stock, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &stock, nil
+}
@@ -0,0 +1,480 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"stockify/internal/core"
+	"stockify/internal/database"
+	"stockify/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var baseTime = time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func testStocks() []core.Stock {
+	return []core.Stock{
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker A", Action: "target raised by", RatingTo: "Buy", Time: baseTime},
+		{Ticker: "MSFT", Company: "Microsoft", Brokerage: "Broker B", Action: "upgraded by", RatingTo: "Outperform", Time: baseTime.Add(1 * time.Hour)},
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker C", Action: "reiterated by", RatingTo: "Hold", Time: baseTime.Add(2 * time.Hour)},
+		{Ticker: "GOOG", Company: "Alphabet", Brokerage: "Broker A", Action: "initiated by", RatingTo: "Buy", Time: baseTime.Add(3 * time.Hour)},
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Broker B", Action: "downgraded by", RatingTo: "Sell", Time: baseTime.Add(4 * time.Hour)},
+	}
+}
+
+func newSQLiteStore(t *testing.T) *store.StockStore {
+	t.Helper()
+
+	ctx := context.Background()
+	db := database.Connect("sqlite://" + filepath.Join(t.TempDir(), "stockify.db"))
+	_, err := database.MigrateUp(ctx, db)
+	require.NoError(t, err)
+
+	stocks := testStocks()
+	require.NoError(t, db.Create(&stocks).Error)
+
+	return store.NewStockStore(db, time.Second)
+}
+
+func newMemoryStore(t *testing.T) *store.MemoryStore {
+	t.Helper()
+	return store.NewMemoryStore(testStocks())
+}
+
+// forEachStore runs fn against every StockStoreInterface implementation so
+// that they are held to the same semantics.
+func forEachStore(t *testing.T, fn func(t *testing.T, s store.StockStoreInterface)) {
+	t.Run("sqlite", func(t *testing.T) { fn(t, newSQLiteStore(t)) })
+	t.Run("memory", func(t *testing.T) { fn(t, newMemoryStore(t)) })
+}
+
+func tickers[T any](rows []T, ticker func(T) string) []string {
+	result := make([]string, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, ticker(row))
+	}
+	return result
+}
+
+func stockTicker(s core.Stock) string        { return s.Ticker }
+func latestTicker(s core.LatestStock) string { return s.Ticker }
+
+func TestStockStore_GetStocks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+
+		stocks, total, err := s.GetStocks(ctx, store.GetStocksParams{Page: 1, PageSize: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+		assert.Equal(t, []string{"AAPL", "GOOG"}, tickers(stocks, stockTicker), "default order is time DESC")
+
+		stocks, total, err = s.GetStocks(ctx, store.GetStocksParams{Search: "apple", SortBy: "time", SortOrder: "asc", Page: 2, PageSize: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		require.Len(t, stocks, 1)
+		assert.Equal(t, "Sell", stocks[0].RatingTo)
+
+		stocks, _, err = s.GetStocks(ctx, store.GetStocksParams{SortBy: "ticker", SortOrder: "desc"})
+		require.NoError(t, err)
+		assert.Equal(t, "MSFT", stocks[0].Ticker)
+		assert.Equal(t, []uint{1, 3, 5}, []uint{stocks[2].ID, stocks[3].ID, stocks[4].ID}, "ties are broken by ascending ID")
+
+		latest, _, err := s.GetLatestStocks(ctx, store.GetStocksParams{SortBy: "brokerage", SortOrder: "desc"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"MSFT", "AAPL", "GOOG"}, tickers(latest, latestTicker), "the Broker B tie is broken by ID")
+	})
+}
+
+func TestStockStore_RejectsInvalidSort(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+		var sortErr *store.InvalidSortError
+
+		_, _, err := s.GetStocks(ctx, store.GetStocksParams{SortBy: "(SELECT 1)"})
+		require.ErrorAs(t, err, &sortErr)
+		assert.Equal(t, "SortBy", sortErr.Field)
+		assert.Equal(t, store.SortColumns, sortErr.Allowed)
+
+		_, _, err = s.GetStocks(ctx, store.GetStocksParams{SortBy: "event_count"})
+		require.ErrorAs(t, err, &sortErr, "event_count only exists in the latest view")
+
+		_, _, err = s.GetLatestStocks(ctx, store.GetStocksParams{SortBy: "ticker", SortOrder: "asc; DROP TABLE stocks"})
+		require.ErrorAs(t, err, &sortErr)
+		assert.Equal(t, "SortOrder", sortErr.Field)
+
+		err = s.StreamStocks(ctx, store.GetStocksParams{SortBy: "ticker desc, id"}, func(core.Stock) error { return nil })
+		require.ErrorAs(t, err, &sortErr)
+
+		stocks, _, err := s.GetLatestStocks(ctx, store.GetStocksParams{SortBy: "Event_Count", SortOrder: "DESC"})
+		require.NoError(t, err, "columns and orders are matched case-insensitively")
+		assert.Equal(t, "AAPL", stocks[0].Ticker)
+	})
+}
+
+func TestStockStore_GetLatestStocks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+
+		stocks, total, err := s.GetLatestStocks(ctx, store.GetStocksParams{Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, []string{"AAPL", "GOOG", "MSFT"}, tickers(stocks, latestTicker))
+		assert.Equal(t, int64(3), stocks[0].EventCount)
+		assert.Equal(t, "Sell", stocks[0].RatingTo, "the most recent event of the ticker is returned")
+
+		stocks, total, err = s.GetLatestStocks(ctx, store.GetStocksParams{Search: "o", SortBy: "ticker", SortOrder: "asc", Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, []string{"GOOG", "MSFT"}, tickers(stocks, latestTicker))
+	})
+}
+
+func TestStockStore_GetStockByTicker(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+
+		stock, err := s.GetStockByTicker(ctx, "msft", false)
+		require.NoError(t, err)
+		require.NotNil(t, stock)
+		assert.Equal(t, "Microsoft", stock.Company)
+
+		stock, err = s.GetStockByTicker(ctx, "UNKNOWN", false)
+		require.NoError(t, err)
+		assert.Nil(t, stock)
+
+		count, err := s.CountStocks(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+	})
+}
+
+func TestStockStore_SoftDeleteLifecycle(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+
+		msft, err := s.GetStockByTicker(ctx, "MSFT", false)
+		require.NoError(t, err)
+		require.NotNil(t, msft)
+
+		purged, err := s.PurgeStock(ctx, msft.ID)
+		require.NoError(t, err)
+		assert.False(t, purged, "visible events cannot be purged")
+
+		deleted, err := s.SoftDeleteStock(ctx, msft.ID)
+		require.NoError(t, err)
+		require.NotNil(t, deleted)
+		assert.True(t, deleted.DeletedAt.Valid)
+
+		again, err := s.SoftDeleteStock(ctx, msft.ID)
+		require.NoError(t, err)
+		assert.Nil(t, again, "an event cannot be deleted twice")
+
+		_, total, err := s.GetStocks(ctx, store.GetStocksParams{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), total)
+
+		latest, _, err := s.GetLatestStocks(ctx, store.GetStocksParams{})
+		require.NoError(t, err)
+		assert.NotContains(t, tickers(latest, latestTicker), "MSFT")
+
+		trash, total, err := s.GetDeletedStocks(ctx, store.GetStocksParams{Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, []string{"MSFT"}, tickers(trash, stockTicker))
+
+		restored, err := s.RestoreStock(ctx, msft.ID)
+		require.NoError(t, err)
+		require.NotNil(t, restored)
+		assert.False(t, restored.DeletedAt.Valid)
+
+		count, err := s.CountStocks(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+
+		_, err = s.SoftDeleteStock(ctx, msft.ID)
+		require.NoError(t, err)
+		purged, err = s.PurgeStock(ctx, msft.ID)
+		require.NoError(t, err)
+		assert.True(t, purged)
+
+		trash, _, err = s.GetDeletedStocks(ctx, store.GetStocksParams{})
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+
+		restored, err = s.RestoreStock(ctx, msft.ID)
+		require.NoError(t, err)
+		assert.Nil(t, restored)
+	})
+}
+
+func TestStockStore_AuditTrail(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		audit := s.(store.AuditStoreInterface)
+		syncCtx := core.WithActor(context.Background(), core.Actor{Type: core.ActorTypeSync, ID: "run-1"})
+		adminCtx := core.WithActor(context.Background(), core.Actor{Type: core.ActorTypeAPIKey, ID: "alice"})
+
+		created := core.Stock{Ticker: "NVDA", Company: "NVIDIA", Brokerage: "Broker D", Action: "initiated by", RatingTo: "Buy", Time: baseTime}
+		exists, err := s.HasStockEvent(syncCtx, created)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		require.NoError(t, s.CreateStocks(syncCtx, &created))
+		assert.NotZero(t, created.ID)
+
+		exists, err = s.HasStockEvent(syncCtx, created)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		_, err = s.SoftDeleteStock(adminCtx, created.ID)
+		require.NoError(t, err)
+		_, err = s.RestoreStock(adminCtx, created.ID)
+		require.NoError(t, err)
+
+		entries, total, err := audit.ListAuditEntries(context.Background(), store.AuditQueryParams{StockID: created.ID, Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), total)
+		assert.Equal(t, core.AuditActionUpdate, entries[0].Action, "entries are returned newest first")
+		assert.Equal(t, core.AuditActionDelete, entries[1].Action)
+		assert.Equal(t, core.AuditActionCreate, entries[2].Action)
+		assert.Nil(t, entries[2].Before)
+		assert.Contains(t, string(entries[2].After), `"ticker":"NVDA"`)
+		assert.Equal(t, "run-1", entries[2].ActorID)
+
+		entries, total, err = audit.ListAuditEntries(context.Background(), store.AuditQueryParams{ActorType: core.ActorTypeAPIKey, ActorID: "alice", Ticker: "nvda"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, entries, 2)
+
+		_, total, err = audit.ListAuditEntries(context.Background(), store.AuditQueryParams{From: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+}
+
+func TestStockStore_ArchiveStocks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+
+		msft, err := s.GetStockByTicker(ctx, "MSFT", false)
+		require.NoError(t, err)
+		require.NotNil(t, msft)
+		_, err = s.SoftDeleteStock(ctx, msft.ID)
+		require.NoError(t, err)
+
+		archived, err := s.ArchiveStocks(ctx, baseTime.Add(90*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), archived, "soft-deleted events are archived too")
+
+		stocks, total, err := s.GetStocks(ctx, store.GetStocksParams{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, []string{"AAPL", "GOOG", "AAPL"}, tickers(stocks, stockTicker))
+
+		stocks, total, err = s.GetStocks(ctx, store.GetStocksParams{IncludeArchived: true, SortBy: "time", SortOrder: "asc", Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), total, "archived events stay hidden once soft-deleted")
+		assert.Equal(t, "Buy", stocks[0].RatingTo)
+
+		latest, _, err := s.GetLatestStocks(ctx, store.GetStocksParams{SortBy: "ticker", IncludeArchived: true})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), latest[0].EventCount)
+		latest, _, err = s.GetLatestStocks(ctx, store.GetStocksParams{SortBy: "ticker"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), latest[0].EventCount)
+
+		exists, err := s.HasStockEvent(ctx, testStocks()[0])
+		require.NoError(t, err)
+		assert.True(t, exists, "archived events are not synced again")
+
+		first, err := s.GetStockByTicker(ctx, "AAPL", false)
+		require.NoError(t, err)
+		assert.Equal(t, "Hold", first.RatingTo)
+		first, err = s.GetStockByTicker(ctx, "AAPL", true)
+		require.NoError(t, err)
+		assert.Equal(t, "Buy", first.RatingTo, "the archived event comes back with includeArchived")
+
+		events, err := s.GetStocksByTickers(ctx, []string{"AAPL"}, false)
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+		events, err = s.GetStocksByTickers(ctx, []string{"AAPL"}, true)
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		assert.Equal(t, "Buy", events[2].RatingTo, "the archived event is the oldest")
+
+		entries, total, err := s.(store.AuditStoreInterface).ListAuditEntries(ctx, store.AuditQueryParams{Action: core.AuditActionArchive})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total, "every archived event is audited")
+		assert.ElementsMatch(t, []string{"AAPL", "MSFT"}, tickers(entries, func(e core.AuditEntry) string { return e.Ticker }))
+
+		archived, err = s.ArchiveStocks(ctx, baseTime.Add(90*time.Minute))
+		require.NoError(t, err)
+		assert.Zero(t, archived)
+	})
+}
+
+func TestStockStore_StreamStocks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+		var streamed []core.Stock
+
+		err := s.StreamStocks(ctx, store.GetStocksParams{Search: "apple", SortBy: "time", SortOrder: "asc", Page: 1, PageSize: 1}, func(stock core.Stock) error {
+			streamed = append(streamed, stock)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, streamed, 3, "pagination is ignored")
+		assert.Equal(t, []string{"Buy", "Hold", "Sell"}, []string{streamed[0].RatingTo, streamed[1].RatingTo, streamed[2].RatingTo})
+		assert.NotZero(t, streamed[0].ID)
+
+		stop := errors.New("stop")
+		calls := 0
+		err = s.StreamStocks(ctx, store.GetStocksParams{}, func(core.Stock) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestStockStore_LastModified(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+
+		initial, err := s.LastModified(ctx)
+		require.NoError(t, err)
+		assert.False(t, initial.IsZero())
+
+		previous := initial
+		changes := []func(id uint) error{
+			func(id uint) error { _, err := s.SoftDeleteStock(ctx, id); return err },
+			func(id uint) error { _, err := s.RestoreStock(ctx, id); return err },
+			func(id uint) error { _, err := s.SoftDeleteStock(ctx, id); return err },
+			func(id uint) error { _, err := s.PurgeStock(ctx, id); return err },
+			func(uint) error { _, err := s.ArchiveStocks(ctx, baseTime.Add(time.Hour)); return err },
+		}
+
+		msft, err := s.GetStockByTicker(ctx, "MSFT", false)
+		require.NoError(t, err)
+		require.NotNil(t, msft)
+
+		for i, change := range changes {
+			time.Sleep(5 * time.Millisecond)
+			require.NoError(t, change(msft.ID))
+
+			lastModified, err := s.LastModified(ctx)
+			require.NoError(t, err)
+			assert.True(t, lastModified.After(previous), "change %d must advance LastModified", i)
+			previous = lastModified
+		}
+	})
+}
+
+func TestStockStore_GetStocksByTickers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+
+		stocks, err := s.GetStocksByTickers(ctx, []string{"aapl", "GOOG", "UNKNOWN"}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"AAPL", "GOOG", "AAPL", "AAPL"}, tickers(stocks, stockTicker), "newest first")
+
+		stocks, err = s.GetStocksByTickers(ctx, nil, false)
+		require.NoError(t, err)
+		assert.Empty(t, stocks)
+	})
+}
+
+func TestStockStore_GetStocksAfterID(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+
+		all, err := s.GetStocksAfterID(ctx, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, all, 5)
+		for i := 1; i < len(all); i++ {
+			assert.Less(t, all[i-1].ID, all[i].ID, "ID order")
+		}
+
+		_, err = s.SoftDeleteStock(ctx, all[2].ID)
+		require.NoError(t, err)
+
+		page, err := s.GetStocksAfterID(ctx, all[0].ID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{all[1].ID, all[3].ID}, []uint{page[0].ID, page[1].ID}, "deleted events are skipped")
+
+		page, err = s.GetStocksAfterID(ctx, all[4].ID, 2)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+}
+
+func TestStockStore_GetBrokerages(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+
+		msft, err := s.GetStockByTicker(ctx, "MSFT", false)
+		require.NoError(t, err)
+		_, err = s.SoftDeleteStock(ctx, msft.ID)
+		require.NoError(t, err)
+
+		brokerages, err := s.GetBrokerages(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, []store.BrokerageSummary{
+			{Name: "Broker A", EventCount: 2},
+			{Name: "Broker B", EventCount: 1},
+			{Name: "Broker C", EventCount: 1},
+		}, brokerages)
+
+		brokerages, err = s.GetBrokerages(ctx, "er c")
+		require.NoError(t, err)
+		assert.Equal(t, []store.BrokerageSummary{{Name: "Broker C", EventCount: 1}}, brokerages)
+	})
+}
+
+func TestStockStore_SyncRuns(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.StockStoreInterface) {
+		ctx := context.Background()
+		runs := s.(store.SyncStoreInterface)
+
+		require.NoError(t, s.(store.PingerInterface).Ping(ctx))
+
+		last, err := runs.LastSyncRun(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, last, "no run recorded yet")
+
+		stats, err := runs.SyncStats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, core.SyncStats{}, stats)
+
+		for i, runID := range []string{"first", "latest", "older", "failed"} {
+			finished := baseTime.Add(time.Duration([]int{1, 3, 2, 4}[i]) * time.Hour)
+			run := core.SyncRun{RunID: runID, StartedAt: finished.Add(-time.Minute), FinishedAt: finished, Pages: 2, Created: i, Skipped: 2, Failed: 1}
+			if runID == "failed" {
+				run.ErrorMessage = "poblando: error API no recuperable (código 401)"
+			}
+			require.NoError(t, runs.RecordSyncRun(ctx, &run))
+			assert.NotZero(t, run.ID)
+		}
+
+		last, err = runs.LastSyncRun(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, last)
+		assert.Equal(t, "latest", last.RunID, "failed runs are not a success")
+		assert.True(t, last.FinishedAt.Equal(baseTime.Add(3*time.Hour)))
+		assert.Equal(t, 1, last.Created)
+
+		stats, err = runs.SyncStats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), stats.Pages)
+		assert.Equal(t, int64(6), stats.Created)
+		assert.Equal(t, int64(8), stats.Skipped)
+		assert.Equal(t, int64(4), stats.Failed)
+		assert.Equal(t, int64(1), stats.FailedRuns)
+		require.NotNil(t, stats.LastSuccess)
+		assert.Equal(t, "latest", stats.LastSuccess.RunID)
+	})
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"stockify/internal/core"
+
+	"gorm.io/gorm"
+)
+
+// RecordSyncRun stores a sync run, successful or not.
+func (s *StockStore) RecordSyncRun(ctx context.Context, run *core.SyncRun) error {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	return db.Create(run).Error
+}
+
+// LastSyncRun returns the successful run that finished last, or nil if there
+// is none.
+func (s *StockStore) LastSyncRun(ctx context.Context) (*core.SyncRun, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	return lastSyncRun(db)
+}
+
+func lastSyncRun(db *gorm.DB) (*core.SyncRun, error) {
+	var run core.SyncRun
+	err := db.Where("error_message = ''").Order("finished_at DESC").First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// SyncStats adds up every recorded sync run.
+func (s *StockStore) SyncStats(ctx context.Context) (core.SyncStats, error) {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	var totals struct {
+		Pages, Created, Skipped, Failed, FailedRuns int64
+	}
+	err := db.Model(&core.SyncRun{}).
+		Select("COALESCE(SUM(pages), 0) AS pages, COALESCE(SUM(created), 0) AS created, COALESCE(SUM(skipped), 0) AS skipped, " +
+			"COALESCE(SUM(failed), 0) AS failed, " +
+			"COALESCE(SUM(CASE WHEN error_message <> '' THEN 1 ELSE 0 END), 0) AS failed_runs").
+		Scan(&totals).Error
+	if err != nil {
+		return core.SyncStats{}, err
+	}
+
+	lastSuccess, err := lastSyncRun(db)
+	if err != nil {
+		return core.SyncStats{}, err
+	}
+
+	return core.SyncStats{
+		Pages:       totals.Pages,
+		Created:     totals.Created,
+		Skipped:     totals.Skipped,
+		Failed:      totals.Failed,
+		FailedRuns:  totals.FailedRuns,
+		LastSuccess: lastSuccess,
+	}, nil
+}
+
+// Ping checks the database connection within the query timeout.
+func (s *StockStore) Ping(ctx context.Context) error {
+	db, cancel := s.conn(ctx)
+	defer cancel()
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(db.Statement.Context)
+}
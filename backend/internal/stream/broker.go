@@ -0,0 +1,147 @@
+// Package stream fans newly created rating events out to live subscribers,
+// such as the Server-Sent Events clients of the API.
+package stream
+
+import (
+	"stockify/internal/core"
+	"strings"
+	"sync"
+)
+
+// subscriptionBuffer is how many events a subscriber may fall behind before
+// it is dropped.
+const subscriptionBuffer = 256
+
+// Filter selects events by ticker, brokerage and action. Empty fields match
+// every event; the others are compared case-insensitively.
+type Filter struct {
+	Ticker    string
+	Brokerage string
+	Action    string
+}
+
+func (f Filter) Matches(stock core.Stock) bool {
+	return matches(f.Ticker, stock.Ticker) && matches(f.Brokerage, stock.Brokerage) && matches(f.Action, stock.Action)
+}
+
+func matches(want, got string) bool {
+	return want == "" || strings.EqualFold(want, got)
+}
+
+// Broker delivers published events to every subscriber whose filter they
+// match. Publishing never blocks: a subscriber that falls behind is dropped
+// and its channel closed, so that it reconnects and replays what it missed
+// from the store.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	done        chan struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[*Subscription]struct{}), done: make(chan struct{})}
+}
+
+// Subscription receives the published events matching its filter until it is
+// closed or dropped.
+type Subscription struct {
+	broker *Broker
+	filter Filter
+	events chan core.Stock
+}
+
+// Subscribe registers a subscriber for the events matching filter. Callers
+// must Close the subscription once done with it.
+func (b *Broker) Subscribe(filter Filter) *Subscription {
+	sub := &Subscription{broker: b, filter: filter, events: make(chan core.Stock, subscriptionBuffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	select {
+	case <-b.done:
+		close(sub.events)
+	default:
+		b.subscribers[sub] = struct{}{}
+	}
+
+	return sub
+}
+
+// Events returns the channel of matching events. It is closed when the
+// subscription is closed or dropped for falling behind.
+func (s *Subscription) Events() <-chan core.Stock {
+	return s.events
+}
+
+// Close unsubscribes. It may be called more than once.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.remove(s)
+}
+
+// remove must be called with b.mu held.
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Publish delivers stocks, in order, to every matching subscriber.
+func (b *Broker) Publish(stocks []core.Stock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		for _, stock := range stocks {
+			if !sub.filter.Matches(stock) {
+				continue
+			}
+
+			select {
+			case sub.events <- stock:
+			default:
+				b.remove(sub)
+			}
+
+			if _, ok := b.subscribers[sub]; !ok {
+				break
+			}
+		}
+	}
+}
+
+// Close ends every subscription, and those made afterwards, so that their
+// consumers return when the server shuts down. It may be called more than
+// once.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return
+	default:
+		close(b.done)
+	}
+
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+}
+
+// Done is closed once the broker is closed.
+func (b *Broker) Done() <-chan struct{} {
+	return b.done
+}
+
+// Subscribers returns the number of active subscriptions.
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers)
+}
@@ -0,0 +1,83 @@
+package stream
+
+import (
+	"stockify/internal/core"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func stock(id uint, ticker, brokerage, action string) core.Stock {
+	return core.Stock{Model: gorm.Model{ID: id}, Ticker: ticker, Brokerage: brokerage, Action: action}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	event := stock(1, "AAPL", "Broker A", "upgraded by")
+
+	assert.True(t, Filter{}.Matches(event))
+	assert.True(t, Filter{Ticker: "aapl", Brokerage: "broker a", Action: "Upgraded By"}.Matches(event))
+	assert.False(t, Filter{Ticker: "MSFT"}.Matches(event))
+	assert.False(t, Filter{Ticker: "AAPL", Action: "downgraded by"}.Matches(event))
+}
+
+func TestBroker_PublishesMatchingEvents(t *testing.T) {
+	broker := NewBroker()
+	apple := broker.Subscribe(Filter{Ticker: "AAPL"})
+	all := broker.Subscribe(Filter{})
+	defer apple.Close()
+	defer all.Close()
+
+	broker.Publish([]core.Stock{stock(1, "AAPL", "Broker A", "upgraded by"), stock(2, "MSFT", "Broker B", "initiated by")})
+
+	require.Len(t, apple.Events(), 1)
+	assert.Equal(t, uint(1), (<-apple.Events()).ID)
+	require.Len(t, all.Events(), 2)
+	assert.Equal(t, uint(1), (<-all.Events()).ID)
+	assert.Equal(t, uint(2), (<-all.Events()).ID)
+}
+
+func TestBroker_DropsSlowSubscribers(t *testing.T) {
+	broker := NewBroker()
+	slow := broker.Subscribe(Filter{})
+
+	events := make([]core.Stock, subscriptionBuffer+1)
+	for i := range events {
+		events[i] = stock(uint(i+1), "AAPL", "Broker A", "upgraded by")
+	}
+	broker.Publish(events)
+
+	assert.Equal(t, 0, broker.Subscribers())
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	assert.Equal(t, subscriptionBuffer, received, "the buffered events are still delivered before the channel closes")
+
+	slow.Close()
+}
+
+func TestBroker_Close(t *testing.T) {
+	broker := NewBroker()
+	sub := broker.Subscribe(Filter{})
+
+	broker.Close()
+	broker.Close()
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok, "open subscriptions end")
+	assert.Equal(t, 0, broker.Subscribers())
+	sub.Close()
+
+	late := broker.Subscribe(Filter{})
+	_, ok = <-late.Events()
+	assert.False(t, ok, "subscriptions made after Close end at once")
+	assert.Equal(t, 0, broker.Subscribers())
+
+	select {
+	case <-broker.Done():
+	default:
+		t.Fatal("Done is not closed")
+	}
+}
@@ -0,0 +1,58 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"stockify/internal/store"
+	"time"
+)
+
+// CacheRefreshService invalidates the cache of query results when the rating
+// events change by means other than the server itself, mainly when the
+// datasync command, which runs in another process, stores a sync run.
+type CacheRefreshService struct {
+	source   store.StockStoreInterface
+	cache    *store.CachingStore
+	interval time.Duration
+}
+
+// NewCacheRefreshService watches source, which must not be cached, for
+// changes and invalidates cache when there are any.
+func NewCacheRefreshService(source store.StockStoreInterface, cache *store.CachingStore, interval time.Duration) *CacheRefreshService {
+	return &CacheRefreshService{source: source, cache: cache, interval: interval}
+}
+
+// Run checks when the rating events last changed every interval until ctx is
+// cancelled, invalidating the cache whenever that moves forward. The check is
+// the one behind Last-Modified, so it reads one index entry per table.
+func (s *CacheRefreshService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	lastModified, err := s.source.LastModified(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Caché: no se pudo leer la última modificación", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		latest, err := s.source.LastModified(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.WarnContext(ctx, "Caché: no se pudo leer la última modificación", "error", err)
+			}
+			continue
+		}
+
+		if latest.After(lastModified) {
+			slog.DebugContext(ctx, "Caché: datos modificados, invalidando", "last_modified", latest)
+			s.cache.Invalidate()
+			lastModified = latest
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package tasks
+
+import (
+	"context"
+	"stockify/internal/core"
+	"stockify/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheRefreshService_SyncRunEvictsCachedPages(t *testing.T) {
+	database := store.NewMemoryStore([]core.Stock{{Ticker: "AAPL", Time: time.Now()}})
+	cache := store.NewCachingStore(database, 10, time.Hour)
+	ctx := context.Background()
+	params := store.GetStocksParams{Page: 1, PageSize: 10}
+
+	_, total, err := cache.GetStocks(ctx, params)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		NewCacheRefreshService(database, cache, 5*time.Millisecond).Run(runCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// A sync run in another process writes straight to the database,
+	// bypassing the cache. Give the service time to read the starting point
+	// first.
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, database.CreateStocks(ctx, &core.Stock{Ticker: "MSFT", Time: time.Now()}))
+	require.NoError(t, database.RecordSyncRun(ctx, &core.SyncRun{RunID: "run", StartedAt: time.Now(), FinishedAt: time.Now(), Pages: 1, Created: 1}))
+
+	assert.Eventually(t, func() bool {
+		_, total, err := cache.GetStocks(ctx, params)
+		return err == nil && total == 2
+	}, time.Second, 5*time.Millisecond, "the cached page is still served after the sync run")
+}
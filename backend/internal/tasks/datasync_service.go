@@ -1,19 +1,23 @@
 package tasks
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"stockify/internal/config"
 	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/logging"
+	"stockify/internal/store"
 	"strconv"
 	"strings"
 	"time"
-
-	"gorm.io/gorm"
 )
 
 type ExtAPIResponse struct {
@@ -34,12 +38,26 @@ type ExtAPIStockItem struct {
 }
 
 type DataSyncService struct {
-	db  *gorm.DB
-	cfg *config.Config
+	stockStore store.StockStoreInterface
+	syncStore  store.SyncStoreInterface
+	cfg        *config.Config
 }
 
-func NewDataSyncService(db *gorm.DB, cfg *config.Config) *DataSyncService {
-	return &DataSyncService{db: db, cfg: cfg}
+// NewDataSyncService creates the sync, which records each run in runs so that
+// health checks and metrics can report how fresh the data is and how the sync
+// is doing.
+func NewDataSyncService(ss store.StockStoreInterface, runs store.SyncStoreInterface, cfg *config.Config) *DataSyncService {
+	return &DataSyncService{stockStore: ss, syncStore: runs, cfg: cfg}
+}
+
+// newRunID returns a random identifier for a sync run, recorded as the actor
+// of the changes the run makes.
+func newRunID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
 }
 
 func parseMonetaryValue(valueStr string) (*float64, error) {
@@ -58,15 +76,153 @@ func parseMonetaryValue(valueStr string) (*float64, error) {
 	return &val, nil
 }
 
-func (s *DataSyncService) RunPopulation() error {
-	log.Println("Iniciando tarea de población de la base de datos desde API externa...")
+// itemValueError reports a value of an external API item that cannot be
+// parsed. key words the problem for the clients of the import.
+type itemValueError struct {
+	field string
+	value string
+	key   i18n.Key
+	err   error
+}
+
+func (e *itemValueError) Error() string {
+	return fmt.Sprintf("%s: %v", e.field, e.err)
+}
+
+func (e *itemValueError) Unwrap() error {
+	return e.err
+}
+
+// parseStockItem converts an item of the external API into a stock. Values
+// that cannot be parsed are left empty and reported in the returned errors.
+func parseStockItem(item ExtAPIStockItem) (core.Stock, []*itemValueError) {
+	var errs []*itemValueError
+
+	targetFrom, err := parseMonetaryValue(item.TargetFrom)
+	if err != nil {
+		errs = append(errs, &itemValueError{field: "target_from", value: item.TargetFrom, key: i18n.MsgImportInvalidAmount, err: err})
+	}
+
+	targetTo, err := parseMonetaryValue(item.TargetTo)
+	if err != nil {
+		errs = append(errs, &itemValueError{field: "target_to", value: item.TargetTo, key: i18n.MsgImportInvalidAmount, err: err})
+	}
+
+	var ratingFromPtr *string
+
+	if trimmedRF := strings.TrimSpace(item.RatingFrom); trimmedRF != "" {
+		ratingFromPtr = &trimmedRF
+	}
+
+	var parsedTime time.Time
+
+	if strings.TrimSpace(item.Time) != "" {
+		parsedTime, err = time.Parse(time.RFC3339Nano, strings.TrimSpace(item.Time))
+		if err != nil {
+			errs = append(errs, &itemValueError{field: "time", value: item.Time, key: i18n.MsgImportInvalidTime, err: err})
+		}
+	}
+
+	return core.Stock{
+		Ticker:     strings.TrimSpace(item.Ticker),
+		Company:    strings.TrimSpace(item.Company),
+		Brokerage:  strings.TrimSpace(item.Brokerage),
+		Action:     strings.TrimSpace(item.Action),
+		RatingTo:   strings.TrimSpace(item.RatingTo),
+		RatingFrom: ratingFromPtr,
+		TargetTo:   targetTo,
+		TargetFrom: targetFrom,
+		Time:       parsedTime,
+	}, errs
+}
+
+// storeItems stores the items of a page of the external API, skipping the
+// events already stored, including soft-deleted and archived ones, so that a
+// re-sync does not bring back events an admin removed or the retention policy
+// archived. Values that cannot be parsed are logged and stored empty. It
+// returns how many items were created, skipped and failed.
+func (s *DataSyncService) storeItems(ctx context.Context, page int, items []ExtAPIStockItem) (created, skipped, failed int) {
+	for _, apiItem := range items {
+		stockEntry, parseErrs := parseStockItem(apiItem)
+
+		for _, parseErr := range parseErrs {
+			slog.WarnContext(ctx, "Poblando: valor inválido en ítem de API externa", "page", page, "ticker", apiItem.Ticker, "error", parseErr)
+		}
+
+		exists, err := s.stockStore.HasStockEvent(ctx, stockEntry)
+		if err != nil {
+			slog.ErrorContext(ctx, "Poblando: Error verificando duplicados en BD", "page", page, "ticker", stockEntry.Ticker, "error", err)
+			failed++
+			continue
+		}
+		if exists {
+			skipped++
+			continue
+		}
+
+		if err := s.stockStore.CreateStocks(ctx, &stockEntry); err != nil {
+			slog.ErrorContext(ctx, "Poblando: Error guardando stock en BD", "page", page, "ticker", stockEntry.Ticker, "error", err)
+			failed++
+		} else {
+			created++
+		}
+	}
+
+	return created, skipped, failed
+}
+
+// sleepContext pauses for d, returning early with ctx's error if ctx is
+// cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RunPopulation downloads every page of the external API and stores its
+// items. It stops early, returning ctx's error, once ctx is cancelled. Every
+// line logged by the run carries its run_id. The run is recorded whether it
+// succeeds or fails, unless it is cancelled.
+func (s *DataSyncService) RunPopulation(ctx context.Context) error {
+	run := core.SyncRun{RunID: newRunID(), StartedAt: time.Now().UTC()}
+	ctx = core.WithActor(ctx, core.Actor{Type: core.ActorTypeSync, ID: run.RunID})
+	ctx = logging.With(ctx, slog.String("run_id", run.RunID))
+
+	err := s.runPopulation(ctx, &run)
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		// A run cut short by shutdown is not a failure of the sync.
+		slog.WarnContext(ctx, "Sincronización cancelada", "error", err)
+		return err
+	default:
+		slog.ErrorContext(ctx, "Sincronización falló", "error", err)
+		run.ErrorMessage = err.Error()
+	}
+
+	run.FinishedAt = time.Now().UTC()
+	if err := s.syncStore.RecordSyncRun(ctx, &run); err != nil {
+		slog.WarnContext(ctx, "Poblando: no se pudo registrar la ejecución", "error", err)
+	}
+	return err
+}
+
+// runPopulation syncs every page, adding up in run the pages and items
+// processed.
+func (s *DataSyncService) runPopulation(ctx context.Context, run *core.SyncRun) error {
+	slog.InfoContext(ctx, "Iniciando tarea de población de la base de datos desde API externa")
 
 	baseURL := "https://8j5baasof2.execute-api.us-west-2.amazonaws.com/production/swechallenge/list"
 	apiToken := s.cfg.StockAPIToken
 	httpClient := &http.Client{Timeout: 60 * time.Second}
 	currentNextPageToken := ""
 	pageCount := 1
-	totalItemsProcessed := 0
 
 	for {
 		var currentApiURL string
@@ -80,9 +236,9 @@ func (s *DataSyncService) RunPopulation() error {
 		parsedBaseURL.RawQuery = query.Encode()
 		currentApiURL = parsedBaseURL.String()
 
-		log.Printf("Poblando: Obteniendo datos de API externa (Página %d): %s\n", pageCount, currentApiURL)
+		slog.DebugContext(ctx, "Poblando: Obteniendo datos de API externa", "page", pageCount, "url", currentApiURL)
 
-		req, err := http.NewRequest("GET", currentApiURL, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", currentApiURL, nil)
 
 		if err != nil {
 			return fmt.Errorf("poblando: error creando petición HTTP (Página %d): %w", pageCount, err)
@@ -94,19 +250,27 @@ func (s *DataSyncService) RunPopulation() error {
 		resp, err := httpClient.Do(req)
 
 		if err != nil {
-			log.Printf("Poblando: error obteniendo datos de API externa (Página %d): %v. Reintentando en 10 segundos...\n", pageCount, err)
-			time.Sleep(10 * time.Second)
+			if ctx.Err() != nil {
+				return fmt.Errorf("poblando: sincronización cancelada (Página %d): %w", pageCount, ctx.Err())
+			}
+
+			slog.WarnContext(ctx, "Poblando: error obteniendo datos de API externa, reintentando", "page", pageCount, "retry_in", "10s", "error", err)
+			if err := sleepContext(ctx, 10*time.Second); err != nil {
+				return fmt.Errorf("poblando: sincronización cancelada (Página %d): %w", pageCount, err)
+			}
 			continue
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			bodyBytes, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
-			log.Printf("Poblando: petición a API externa falló (Página %d) con código %d: %s.\n", pageCount, resp.StatusCode, string(bodyBytes))
+			slog.WarnContext(ctx, "Poblando: petición a API externa falló", "page", pageCount, "status", resp.StatusCode, "body", string(bodyBytes))
 
 			if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
-				log.Println("Poblando: Error de servidor o límite de peticiones. Reintentando en 20 segundos...")
-				time.Sleep(20 * time.Second)
+				slog.WarnContext(ctx, "Poblando: Error de servidor o límite de peticiones, reintentando", "page", pageCount, "retry_in", "20s")
+				if err := sleepContext(ctx, 20*time.Second); err != nil {
+					return fmt.Errorf("poblando: sincronización cancelada (Página %d): %w", pageCount, err)
+				}
 				continue
 			}
 
@@ -125,75 +289,32 @@ func (s *DataSyncService) RunPopulation() error {
 			return fmt.Errorf("poblando: error decodificando JSON (Página %d): %w\nCuerpo: %s", pageCount, err, string(body))
 		}
 
-		log.Printf("Poblando: Obtenidos %d ítems de API externa (Página %d). Procesando...\n", len(apiResponse.Items), pageCount)
+		slog.DebugContext(ctx, "Poblando: Ítems obtenidos de API externa", "page", pageCount, "items", len(apiResponse.Items))
 		if len(apiResponse.Items) == 0 && (apiResponse.NextPage == nil || *apiResponse.NextPage == "") {
-			log.Println("Poblando: Recibidos 0 ítems y sin token de siguiente página. Asumiendo fin de datos.")
+			slog.InfoContext(ctx, "Poblando: Recibidos 0 ítems y sin token de siguiente página, asumiendo fin de datos", "page", pageCount)
 			break
 		}
 
-		for _, apiItem := range apiResponse.Items {
-			targetFrom, errTFrom := parseMonetaryValue(apiItem.TargetFrom)
-
-			if errTFrom != nil {
-				log.Printf("Poblando (Advertencia Ticker %s): TargetFrom ('%s'): %v", apiItem.Ticker, apiItem.TargetFrom, errTFrom)
-			}
-
-			targetTo, errTTo := parseMonetaryValue(apiItem.TargetTo)
-
-			if errTTo != nil {
-				log.Printf("Poblando (Advertencia Ticker %s): TargetTo ('%s'): %v", apiItem.Ticker, apiItem.TargetTo, errTTo)
-			}
-
-			var ratingFromPtr *string
-
-			if trimmedRF := strings.TrimSpace(apiItem.RatingFrom); trimmedRF != "" {
-				ratingFromPtr = &trimmedRF
-			}
-
-			var parsedTime time.Time
-
-			if strings.TrimSpace(apiItem.Time) != "" {
-				var parseErr error
-				parsedTime, parseErr = time.Parse(time.RFC3339Nano, apiItem.Time)
+		pageCreated, pageSkipped, pageFailed := s.storeItems(ctx, pageCount, apiResponse.Items)
 
-				if parseErr != nil {
-					log.Printf("Poblando (Advertencia Ticker %s): Time ('%s'): %v. Usando valor zero.", apiItem.Time, apiItem.Ticker, parseErr)
-				}
-			}
+		run.Pages++
+		run.Created += pageCreated
+		run.Failed += pageFailed
+		run.Skipped += pageSkipped
 
-			stockEntry := core.Stock{
-				Ticker:     strings.TrimSpace(apiItem.Ticker),
-				Company:    strings.TrimSpace(apiItem.Company),
-				Brokerage:  strings.TrimSpace(apiItem.Brokerage),
-				Action:     strings.TrimSpace(apiItem.Action),
-				RatingTo:   strings.TrimSpace(apiItem.RatingTo),
-				RatingFrom: ratingFromPtr,
-				TargetTo:   targetTo,
-				TargetFrom: targetFrom,
-				Time:       parsedTime,
-			}
-
-			result := s.db.Create(&stockEntry)
-
-			if result.Error != nil {
-				log.Printf("Poblando: Error guardando stock para ticker %s en BD: %v\n", stockEntry.Ticker, result.Error)
-			} else {
-				totalItemsProcessed++
-			}
-		}
-
-		log.Printf("Poblando: Procesados %d ítems para página %d.\n", len(apiResponse.Items), pageCount)
+		slog.InfoContext(ctx, "Poblando: Página procesada", "page", pageCount, "items", len(apiResponse.Items), "created", pageCreated, "skipped", pageSkipped, "failed", pageFailed)
 
 		if apiResponse.NextPage != nil && strings.TrimSpace(*apiResponse.NextPage) != "" {
 			currentNextPageToken = strings.TrimSpace(*apiResponse.NextPage)
 			pageCount++
-			log.Printf("Poblando: Token de siguiente página encontrado: '%s'. Preparando siguiente página.\n", currentNextPageToken)
+			slog.DebugContext(ctx, "Poblando: Token de siguiente página encontrado", "next_page", currentNextPageToken)
 		} else {
-			log.Println("Poblando: No hay más páginas para obtener.")
+			slog.DebugContext(ctx, "Poblando: No hay más páginas para obtener")
 			break
 		}
 	}
 
-	log.Printf("Tarea de población finalizada. Total de ítems procesados (nuevos registros creados o intentados): %d\n", totalItemsProcessed)
+	slog.InfoContext(ctx, "Tarea de población finalizada", "pages", run.Pages, "created", run.Created, "skipped", run.Skipped, "failed", run.Failed)
+
 	return nil
 }
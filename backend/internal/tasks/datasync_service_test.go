@@ -0,0 +1,69 @@
+package tasks
+
+import (
+	"context"
+	"stockify/internal/config"
+	"stockify/internal/core"
+	"stockify/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSyncService_SkipsDeletedAndArchivedEvents(t *testing.T) {
+	ctx := context.Background()
+	old := time.Date(2020, 1, 2, 15, 0, 0, 0, time.UTC)
+	recent := time.Now().UTC().Truncate(time.Second)
+
+	memoryStore := store.NewMemoryStore([]core.Stock{
+		{Ticker: "AAPL", Brokerage: "Broker A", Action: "upgraded by", RatingTo: "Buy", Time: recent},
+		{Ticker: "MSFT", Brokerage: "Broker B", Action: "initiated by", RatingTo: "Buy", Time: old},
+	})
+	deleted, err := memoryStore.GetStockByTicker(ctx, "AAPL", false)
+	require.NoError(t, err)
+	_, err = memoryStore.SoftDeleteStock(ctx, deleted.ID)
+	require.NoError(t, err)
+	archived, err := memoryStore.ArchiveStocks(ctx, old.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), archived)
+
+	items := []ExtAPIStockItem{
+		{Ticker: "AAPL", Brokerage: "Broker A", Action: "upgraded by", RatingTo: "Buy", Time: recent.Format(time.RFC3339)},
+		{Ticker: "MSFT", Brokerage: "Broker B", Action: "initiated by", RatingTo: "Buy", Time: old.Format(time.RFC3339)},
+		{Ticker: "GOOG", Brokerage: "Broker C", Action: "initiated by", RatingTo: "Hold", Time: recent.Format(time.RFC3339)},
+	}
+
+	sync := NewDataSyncService(memoryStore, memoryStore, &config.Config{})
+	created, skipped, failed := sync.storeItems(ctx, 1, items)
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 2, skipped, "the soft-deleted and the archived events are already stored")
+	assert.Zero(t, failed)
+
+	stocks, total, err := memoryStore.GetStocks(ctx, store.GetStocksParams{Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total, "the deleted event stays deleted")
+	assert.Equal(t, "GOOG", stocks[0].Ticker)
+
+	stocks, total, err = memoryStore.GetStocks(ctx, store.GetStocksParams{Page: 1, PageSize: 10, SortBy: "ticker", IncludeArchived: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total, "the archived event is not duplicated")
+	assert.Equal(t, []string{"GOOG", "MSFT"}, []string{stocks[0].Ticker, stocks[1].Ticker})
+}
+
+func TestDataSyncService_StoresItemsWithInvalidValues(t *testing.T) {
+	ctx := context.Background()
+	memoryStore := store.NewMemoryStore(nil)
+
+	sync := NewDataSyncService(memoryStore, memoryStore, &config.Config{})
+	created, skipped, failed := sync.storeItems(ctx, 1, []ExtAPIStockItem{
+		{Ticker: "AAPL", TargetTo: "n/a", Time: time.Now().Format(time.RFC3339)},
+	})
+	assert.Equal(t, []int{1, 0, 0}, []int{created, skipped, failed})
+
+	stock, err := memoryStore.GetStockByTicker(ctx, "AAPL", false)
+	require.NoError(t, err)
+	require.NotNil(t, stock)
+	assert.Nil(t, stock.TargetTo, "the unparseable target is stored empty")
+}
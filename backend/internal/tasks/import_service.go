@@ -0,0 +1,258 @@
+package tasks
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/store"
+	"strings"
+)
+
+const (
+	ImportRowAccepted = "accepted"
+	ImportRowRejected = "rejected"
+)
+
+// ErrInvalidImportFile is matched by the errors Import returns when the CSV
+// itself is invalid, as opposed to individual rows being rejected.
+var ErrInvalidImportFile = errors.New("archivo CSV inválido")
+
+// ImportError is why a row or the whole file is rejected, as a message of the
+// catalog and the values of its placeholders, so that the API can word it in
+// the language of the request.
+type ImportError struct {
+	Key    i18n.Key
+	Params i18n.Params
+}
+
+// Localize words the error in lang.
+func (e ImportError) Localize(lang i18n.Lang) string {
+	return i18n.T(lang, e.Key, e.Params)
+}
+
+// InvalidImportFileError is returned by Import when the CSV itself is
+// invalid. It matches ErrInvalidImportFile.
+type InvalidImportFileError struct {
+	Reason ImportError
+	err    error
+}
+
+func (e *InvalidImportFileError) Error() string {
+	return ErrInvalidImportFile.Error() + ": " + e.Reason.Localize(i18n.Default)
+}
+
+func (e *InvalidImportFileError) Unwrap() []error {
+	if e.err == nil {
+		return []error{ErrInvalidImportFile}
+	}
+	return []error{ErrInvalidImportFile, e.err}
+}
+
+func invalidImportFile(err error, key i18n.Key, params i18n.Params) error {
+	return &InvalidImportFileError{Reason: ImportError{Key: key, Params: params}, err: err}
+}
+
+// ImportRowResult reports the outcome of one CSV row. Row is the line of the
+// file where the row starts, the header being line 1.
+type ImportRowResult struct {
+	Row     int           `json:"row"`
+	Ticker  string        `json:"ticker"`
+	Status  string        `json:"status"`
+	StockID uint          `json:"stock_id,omitempty"`
+	Errors  []ImportError `json:"errors,omitempty"`
+}
+
+// ImportReport summarizes an import and lists the result of every row.
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Accepted int               `json:"accepted"`
+	Rejected int               `json:"rejected"`
+	Rows     []ImportRowResult `json:"rows"`
+}
+
+// ImportService loads rating events from CSV files whose columns are the
+// fields of ExtAPIStockItem, applying the same parsing rules as the sync.
+type ImportService struct {
+	stockStore store.StockStoreInterface
+}
+
+func NewImportService(ss store.StockStoreInterface) *ImportService {
+	return &ImportService{stockStore: ss}
+}
+
+// importColumns are the CSV column names, the JSON names of the
+// ExtAPIStockItem fields.
+var importColumns = []string{"ticker", "target_from", "target_to", "company", "action", "brokerage", "rating_from", "rating_to", "time"}
+
+// Import validates every row of the CSV read from r and, unless dryRun is
+// set, stores the valid ones in a single transaction, so that a failure
+// stores none of them. Rows are rejected when a value cannot be parsed, the
+// ticker or time is missing, or the event is already stored or repeated
+// earlier in the file. Errors reading the file itself wrap
+// ErrInvalidImportFile.
+func (s *ImportService) Import(ctx context.Context, r io.Reader, dryRun bool) (*ImportReport, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, invalidImportFile(err, i18n.MsgImportEmptyFile, nil)
+	}
+	if err != nil {
+		return nil, readImportError(err)
+	}
+
+	index, err := importColumnIndex(header)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: dryRun, Rows: []ImportRowResult{}}
+	seen := make(map[string]bool)
+
+	// pending holds the accepted events, to be stored once every row has
+	// been validated; pendingRows maps each of them to its row in report.
+	var pending []*core.Stock
+	var pendingRows []int
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, readImportError(err)
+		}
+
+		row, _ := reader.FieldPos(0)
+
+		if len(record) != len(header) {
+			report.Rejected++
+			report.Rows = append(report.Rows, ImportRowResult{
+				Row:    row,
+				Status: ImportRowRejected,
+				Errors: []ImportError{{Key: i18n.MsgImportColumnCount, Params: i18n.Params{"expected": len(header), "received": len(record)}}},
+			})
+			continue
+		}
+
+		item := ExtAPIStockItem{
+			Ticker:     record[index["ticker"]],
+			TargetFrom: record[index["target_from"]],
+			TargetTo:   record[index["target_to"]],
+			Company:    record[index["company"]],
+			Action:     record[index["action"]],
+			Brokerage:  record[index["brokerage"]],
+			RatingFrom: record[index["rating_from"]],
+			RatingTo:   record[index["rating_to"]],
+			Time:       record[index["time"]],
+		}
+
+		stock, result := s.importRow(ctx, item, seen)
+		result.Row = row
+
+		if result.Status == ImportRowAccepted {
+			report.Accepted++
+			pending = append(pending, stock)
+			pendingRows = append(pendingRows, len(report.Rows))
+		} else {
+			report.Rejected++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	if !dryRun && len(pending) > 0 {
+		if err := s.stockStore.CreateStocks(ctx, pending...); err != nil {
+			return nil, fmt.Errorf("importación: error guardando %d eventos: %w", len(pending), err)
+		}
+		for i, stock := range pending {
+			report.Rows[pendingRows[i]].StockID = stock.ID
+		}
+	}
+
+	slog.InfoContext(ctx, "Importación CSV finalizada", "dry_run", dryRun, "accepted", report.Accepted, "rejected", report.Rejected)
+	return report, nil
+}
+
+// importRow validates item, returning the event to store if it is accepted.
+func (s *ImportService) importRow(ctx context.Context, item ExtAPIStockItem, seen map[string]bool) (*core.Stock, ImportRowResult) {
+	stock, parseErrs := parseStockItem(item)
+	result := ImportRowResult{Ticker: stock.Ticker, Status: ImportRowRejected}
+
+	// Unlike the sync, which stores what it can of every item, the import
+	// rejects rows with values it cannot parse or without ticker or time.
+	for _, parseErr := range parseErrs {
+		result.Errors = append(result.Errors, ImportError{Key: parseErr.key, Params: i18n.Params{"column": parseErr.field, "value": parseErr.value}})
+	}
+	if stock.Ticker == "" {
+		result.Errors = append(result.Errors, ImportError{Key: i18n.MsgImportRequired, Params: i18n.Params{"column": "ticker"}})
+	}
+	if strings.TrimSpace(item.Time) == "" {
+		result.Errors = append(result.Errors, ImportError{Key: i18n.MsgImportRequired, Params: i18n.Params{"column": "time"}})
+	}
+	if len(result.Errors) > 0 {
+		return nil, result
+	}
+
+	key := fmt.Sprintf("%s|%s|%s|%s", stock.Ticker, stock.Brokerage, stock.Action, stock.Time)
+	if seen[key] {
+		result.Errors = append(result.Errors, ImportError{Key: i18n.MsgImportRepeatedEvent})
+		return nil, result
+	}
+	seen[key] = true
+
+	exists, err := s.stockStore.HasStockEvent(ctx, stock)
+
+	switch {
+	case err != nil:
+		slog.ErrorContext(ctx, "Importación: Error verificando duplicados en BD", "ticker", stock.Ticker, "error", err)
+		result.Errors = append(result.Errors, ImportError{Key: i18n.MsgImportCheckFailed})
+		return nil, result
+	case exists:
+		result.Errors = append(result.Errors, ImportError{Key: i18n.MsgImportExistingEvent})
+		return nil, result
+	}
+
+	result.Status = ImportRowAccepted
+	return &stock, result
+}
+
+// importColumnIndex maps every expected column to its position in header,
+// failing if a column is missing or unknown.
+func importColumnIndex(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(importColumns, name) {
+			return nil, invalidImportFile(nil, i18n.MsgImportUnknownColumn, i18n.Params{"column": name, "expected": strings.Join(importColumns, ", ")})
+		}
+		index[name] = i
+	}
+
+	for _, name := range importColumns {
+		if _, ok := index[name]; !ok {
+			return nil, invalidImportFile(nil, i18n.MsgImportMissingColumn, i18n.Params{"column": name})
+		}
+	}
+
+	return index, nil
+}
+
+// readImportError classifies an error reading the CSV: malformed CSV makes
+// the file invalid, while other errors, such as the body exceeding its size
+// limit, are returned wrapped.
+func readImportError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return invalidImportFile(err, i18n.MsgImportMalformedCSV, i18n.Params{"line": parseErr.StartLine})
+	}
+	return fmt.Errorf("importación: error leyendo el CSV: %w", err)
+}
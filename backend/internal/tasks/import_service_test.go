@@ -0,0 +1,117 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"stockify/internal/core"
+	"stockify/internal/i18n"
+	"stockify/internal/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const importCSV = `ticker,target_from,target_to,company,action,brokerage,rating_from,rating_to,time
+AAPL,$180.00,"$1,200.50",Apple Inc.,target raised by,Broker A,Hold,Buy,2025-05-01T12:00:00Z
+MSFT,abc,$400,Microsoft,upgraded by,Broker B,,Outperform,2025-05-02T12:00:00Z
+,$1,$2,Nameless,initiated by,Broker C,,Buy,2025-05-03T12:00:00Z
+AAPL,$180.00,"$1,200.50",Apple Inc.,target raised by,Broker A,Hold,Buy,2025-05-01T12:00:00Z
+GOOG,,,Alphabet,initiated by,Broker A,,Buy,2025-04-01T12:00:00Z
+`
+
+func newImportStore() *store.MemoryStore {
+	return store.NewMemoryStore([]core.Stock{
+		{Ticker: "GOOG", Company: "Alphabet", Brokerage: "Broker A", Action: "initiated by", RatingTo: "Buy", Time: time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)},
+	})
+}
+
+func TestImportService_Import(t *testing.T) {
+	memoryStore := newImportStore()
+	ctx := context.Background()
+
+	report, err := NewImportService(memoryStore).Import(ctx, strings.NewReader(importCSV), false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Accepted)
+	assert.Equal(t, 4, report.Rejected)
+	require.Len(t, report.Rows, 5)
+
+	assert.Equal(t, ImportRowResult{Row: 2, Ticker: "AAPL", Status: ImportRowAccepted, StockID: 2}, report.Rows[0])
+	assert.Equal(t, 3, report.Rows[1].Row)
+	assert.Equal(t, []ImportError{{Key: i18n.MsgImportInvalidAmount, Params: i18n.Params{"column": "target_from", "value": "abc"}}}, report.Rows[1].Errors)
+	assert.Equal(t, []ImportError{{Key: i18n.MsgImportRequired, Params: i18n.Params{"column": "ticker"}}}, report.Rows[2].Errors)
+	assert.Equal(t, []ImportError{{Key: i18n.MsgImportRepeatedEvent}}, report.Rows[3].Errors)
+	assert.Equal(t, []ImportError{{Key: i18n.MsgImportExistingEvent}}, report.Rows[4].Errors)
+	assert.Equal(t, `target_from: "abc" is not a valid amount`, report.Rows[1].Errors[0].Localize(i18n.English))
+
+	stock, err := memoryStore.GetStockByTicker(ctx, "AAPL", false)
+	require.NoError(t, err)
+	require.NotNil(t, stock)
+	require.NotNil(t, stock.TargetTo)
+	assert.Equal(t, 1200.5, *stock.TargetTo)
+	assert.Equal(t, "Hold", *stock.RatingFrom)
+}
+
+func TestImportService_DryRun(t *testing.T) {
+	memoryStore := newImportStore()
+	ctx := context.Background()
+
+	report, err := NewImportService(memoryStore).Import(ctx, strings.NewReader(importCSV), true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Accepted)
+	assert.Zero(t, report.Rows[0].StockID)
+
+	count, err := memoryStore.CountStocks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "a dry run does not write")
+}
+
+func TestImportService_InvalidFile(t *testing.T) {
+	header := strings.Join(importColumns, ",")
+	tests := []struct {
+		name string
+		csv  string
+		want ImportError
+	}{
+		{"empty file", "", ImportError{Key: i18n.MsgImportEmptyFile}},
+		{"unknown column", "ticker,price\n", ImportError{Key: i18n.MsgImportUnknownColumn, Params: i18n.Params{"column": "price", "expected": strings.Join(importColumns, ", ")}}},
+		{"missing column", "ticker,company,action,brokerage,rating_from,rating_to,time\n", ImportError{Key: i18n.MsgImportMissingColumn, Params: i18n.Params{"column": "target_from"}}},
+		{"malformed row", header + "\nAAPL,\"$1,,,,,,,\n", ImportError{Key: i18n.MsgImportMalformedCSV, Params: i18n.Params{"line": 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewImportService(newImportStore()).Import(context.Background(), strings.NewReader(tt.csv), false)
+			assert.ErrorIs(t, err, ErrInvalidImportFile)
+
+			var fileErr *InvalidImportFileError
+			require.ErrorAs(t, err, &fileErr)
+			assert.Equal(t, tt.want, fileErr.Reason)
+		})
+	}
+}
+
+type failingCreateStore struct {
+	*store.MemoryStore
+}
+
+func (s failingCreateStore) CreateStocks(ctx context.Context, stocks ...*core.Stock) error {
+	return errors.New("database down")
+}
+
+func TestImportService_StoresNothingOnFailure(t *testing.T) {
+	memoryStore := newImportStore()
+	ctx := context.Background()
+
+	_, err := NewImportService(failingCreateStore{memoryStore}).Import(ctx, strings.NewReader(importCSV), false)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidImportFile)
+
+	count, err := memoryStore.CountStocks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
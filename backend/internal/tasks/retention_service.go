@@ -0,0 +1,39 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"stockify/internal/store"
+	"time"
+)
+
+// RetentionService applies the retention policy, moving rating events older
+// than the configured number of months into the archive table.
+type RetentionService struct {
+	stockStore store.StockStoreInterface
+	months     int
+}
+
+func NewRetentionService(ss store.StockStoreInterface, months int) *RetentionService {
+	return &RetentionService{stockStore: ss, months: months}
+}
+
+// Run archives every event older than the retention period and returns how
+// many were moved.
+func (s *RetentionService) Run(ctx context.Context) (int64, error) {
+	if s.months <= 0 {
+		return 0, fmt.Errorf("retención: el número de meses debe ser mayor que cero (recibido %d)", s.months)
+	}
+
+	cutoff := time.Now().AddDate(0, -s.months, 0)
+	slog.InfoContext(ctx, "Retención: archivando eventos", "before", cutoff.Format(time.RFC3339), "months", s.months)
+
+	archived, err := s.stockStore.ArchiveStocks(ctx, cutoff)
+	if err != nil {
+		return archived, fmt.Errorf("retención: error archivando eventos tras mover %d: %w", archived, err)
+	}
+
+	slog.InfoContext(ctx, "Retención finalizada", "archived", archived)
+	return archived, nil
+}
@@ -0,0 +1,95 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"stockify/internal/store"
+	"stockify/internal/stream"
+	"time"
+)
+
+// streamFeedBatch is how many new events are read at a time on each poll.
+const streamFeedBatch = 500
+
+// StreamFeedService feeds the stream broker from the store, so that live
+// clients receive the events created by any writer: the datasync command,
+// which runs in another process, and the CSV imports of the server.
+type StreamFeedService struct {
+	stockStore store.StockStoreInterface
+	broker     *stream.Broker
+	interval   time.Duration
+}
+
+// NewStreamFeedService creates the feed polling ss, which must not be a
+// CachingStore: a cached page would hide the events written by other
+// processes until it expires.
+func NewStreamFeedService(ss store.StockStoreInterface, broker *stream.Broker, interval time.Duration) *StreamFeedService {
+	return &StreamFeedService{stockStore: ss, broker: broker, interval: interval}
+}
+
+// Run publishes every event created after it starts, checking the store every
+// interval until ctx is cancelled. Events are found by ID, so an event whose
+// transaction commits after one with a higher ID has been published is not
+// published.
+func (s *StreamFeedService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	lastID, err := s.latestID(ctx)
+	started := err == nil
+	if err != nil {
+		slog.WarnContext(ctx, "Stream: no se pudo leer el último evento, reintentando", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !started {
+			if lastID, err = s.latestID(ctx); err != nil {
+				slog.WarnContext(ctx, "Stream: no se pudo leer el último evento, reintentando", "error", err)
+				continue
+			}
+			started = true
+			continue
+		}
+
+		lastID = s.publishAfter(ctx, lastID)
+	}
+}
+
+// latestID returns the ID of the newest visible event, or 0 if there are none.
+func (s *StreamFeedService) latestID(ctx context.Context) (uint, error) {
+	stocks, _, err := s.stockStore.GetStocks(ctx, store.GetStocksParams{SortBy: "id", SortOrder: "desc", Page: 1, PageSize: 1})
+	if err != nil || len(stocks) == 0 {
+		return 0, err
+	}
+	return stocks[0].ID, nil
+}
+
+// publishAfter publishes the events created after lastID and returns the ID
+// of the last one published.
+func (s *StreamFeedService) publishAfter(ctx context.Context, lastID uint) uint {
+	for {
+		stocks, err := s.stockStore.GetStocksAfterID(ctx, lastID, streamFeedBatch)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.WarnContext(ctx, "Stream: error consultando eventos nuevos", "after_id", lastID, "error", err)
+			}
+			return lastID
+		}
+		if len(stocks) == 0 {
+			return lastID
+		}
+
+		s.broker.Publish(stocks)
+		lastID = stocks[len(stocks)-1].ID
+
+		if len(stocks) < streamFeedBatch {
+			return lastID
+		}
+	}
+}
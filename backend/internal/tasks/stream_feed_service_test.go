@@ -0,0 +1,48 @@
+package tasks
+
+import (
+	"context"
+	"stockify/internal/core"
+	"stockify/internal/store"
+	"stockify/internal/stream"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamFeedService_PublishesNewEvents(t *testing.T) {
+	memoryStore := store.NewMemoryStore([]core.Stock{{Ticker: "GOOG"}})
+	broker := stream.NewBroker()
+	sub := broker.Subscribe(stream.Filter{})
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewStreamFeedService(memoryStore, broker, 5*time.Millisecond).Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Events stored before the feed starts are not published; give it time
+	// to read the latest ID before writing.
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, memoryStore.CreateStocks(ctx, &core.Stock{Ticker: "AAPL"}))
+	require.NoError(t, memoryStore.CreateStocks(ctx, &core.Stock{Ticker: "MSFT"}))
+
+	var tickers []string
+	for len(tickers) < 2 {
+		select {
+		case stock := <-sub.Events():
+			tickers = append(tickers, stock.Ticker)
+		case <-time.After(time.Second):
+			t.Fatalf("received %v, want two new events", tickers)
+		}
+	}
+	assert.Equal(t, []string{"AAPL", "MSFT"}, tickers)
+}
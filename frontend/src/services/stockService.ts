@@ -3,19 +3,19 @@ import axios, { type AxiosError } from 'axios';
 const API_BASE_URL = import.meta.env.VITE_APP_API_BASE_URL || 'http://localhost:8080/api';
 
 export interface Stock {
-  ID?: number;
-  CreatedAt?: string;
-  UpdatedAt?: string;
-  DeletedAt?: string | null;
+  id?: number;
   ticker: string;
   company: string;
   brokerage: string;
   action: string;
-  rating_to: string;
   rating_from?: string | null;
-  target_to?: number | null;
+  rating_to: string;
   target_from?: number | null;
+  target_to?: number | null;
   time: string;
+  created_at?: string;
+  updated_at?: string;
+  deleted_at?: string;
 }
 
 export interface FetchParams {
@@ -37,6 +37,7 @@ export interface PaginatedStocksResponse {
 export interface RecommendationReason {
   type: string;
   details: string;
+  params: Record<string, string | number>;
 }
 
 export interface RecommendedStockView extends Stock {